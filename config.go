@@ -13,4 +13,7 @@ type Config struct {
 	Template     templates.Config
 	JSONSecurity jsonsecurity.Config
 	Writer       io.Writer
+
+	// ContextExtractors contribute fields to every logger bound to a context. See ContextExtractor.
+	ContextExtractors []ContextExtractor
 }
@@ -0,0 +1,152 @@
+package mlogger
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Alp4ka/mlogger/field"
+	"github.com/Alp4ka/mlogger/misc"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+var (
+	_eventsMu sync.RWMutex
+	_events   = make(map[string]Event)
+)
+
+// AlertPolicy defines whether the event is sent to contact points.
+type AlertPolicy uint8
+
+const (
+	// AlertPolicyDefault sends the event to contact points as any other record.
+	AlertPolicyDefault AlertPolicy = iota
+	// AlertPolicyNever writes the event with the local writer only.
+	AlertPolicyNever
+)
+
+// Event describes a log record with stable ID that is declared once and emitted using MainLogger.Event. Alert routing
+// and dashboards should rely on ID instead of message text.
+//
+// Example:
+//
+//	var EventLoginFailed = mlogger.MustRegisterEvent(mlogger.Event{
+//		ID:       "auth.login_failed",
+//		Level:    misc.LevelWarn,
+//		Message:  "login failed for user {{ .user_id }}",
+//		Required: []string{"user_id"},
+//	})
+type Event struct {
+	// ID is a stable identifier of the event. It's logged as field.KeyEventID.
+	ID string
+	// Level is a level the event is logged with.
+	Level misc.Level
+	// Message is a text/template rendered with values of the record fields accessed by their keys.
+	Message string
+	// Required keys of fields which must be provided on every emit.
+	Required []string
+	// Alert defines whether the event is sent to contact points.
+	Alert AlertPolicy
+
+	tmpl *template.Template
+}
+
+// RegisterEvent validates the event and adds it to the catalog. IDs must be unique.
+func RegisterEvent(evt Event) (Event, error) {
+	if evt.ID == "" {
+		return Event{}, fmt.Errorf("event id is empty")
+	}
+
+	tmpl, err := template.New(evt.ID).Option("missingkey=zero").Parse(evt.Message)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to parse message of event '%s': %w", evt.ID, err)
+	}
+	evt.tmpl = tmpl
+	evt.Required = append([]string(nil), evt.Required...)
+
+	_eventsMu.Lock()
+	defer _eventsMu.Unlock()
+
+	if _, ok := _events[evt.ID]; ok {
+		return Event{}, fmt.Errorf("event '%s' is already registered", evt.ID)
+	}
+	_events[evt.ID] = evt
+
+	return evt, nil
+}
+
+// MustRegisterEvent the same as RegisterEvent but panics on error. It's intended for package level declarations.
+func MustRegisterEvent(evt Event) Event {
+	registered, err := RegisterEvent(evt)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return registered
+}
+
+// Events returns all registered events sorted by ID. It may be used to generate the catalog of events.
+func Events() []Event {
+	_eventsMu.RLock()
+	events := make([]Event, 0, len(_events))
+	for _, evt := range _events {
+		events = append(events, evt)
+	}
+	_eventsMu.RUnlock()
+
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
+
+// missingFields returns required keys absent in fields.
+func (e Event) missingFields(fields field.Fields) []string {
+	missing := make([]string, 0)
+	for _, key := range e.Required {
+		found := false
+		for _, f := range fields {
+			if f.Key() == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing
+}
+
+// render renders the message of the event using prepared fields. Message of the event that has not been registered
+// is returned as is.
+func (e Event) render(fields field.Fields) string {
+	if e.tmpl == nil {
+		return e.Message
+	}
+
+	data := make(map[string]any, len(fields))
+	for _, f := range fields {
+		data[f.Key()] = f.Value()
+	}
+
+	buf := new(bytes.Buffer)
+	if err := e.tmpl.Execute(buf, data); err != nil {
+		return e.Message
+	}
+
+	return buf.String()
+}
+
+// eventFields returns fields to be appended to the record of the event.
+func (e Event) eventFields(fields field.Fields) field.Fields {
+	ret := field.Fields{field.String(field.KeyEventID, e.ID)}
+	if missing := e.missingFields(fields); len(missing) != 0 {
+		ret = append(ret, field.ErrorNamed(
+			field.KeyEventID+"_FAIL",
+			fmt.Errorf("missing required fields: %s", strings.Join(missing, ", ")),
+		))
+	}
+
+	return ret
+}
@@ -0,0 +1,45 @@
+package mlogger
+
+import (
+	"context"
+	"github.com/Alp4ka/mlogger/field"
+)
+
+// ContextExtractor contributes fields stored in context under keys owned by other packages, e.g. request or tenant
+// IDs. Extractors are run once per logger bound to a context, i.e. in NewProduction, MainLogger.WithContext and L, in
+// the order they are specified in Config.ContextExtractors. Extractors should return no fields for the values absent in
+// the context, since the fields of the logger win over the ones extracted later.
+//
+// Collisions are resolved in favor of the fields already known: the fields of the logger and the ones added with
+// field.WithContextFields always win, among extractors the first one to contribute the key wins. The rest of the
+// fields with the same key are dropped.
+type ContextExtractor func(ctx context.Context) []field.Field
+
+// extractFields runs context extractors skipping the fields with keys from existing.
+func extractFields(extractors []ContextExtractor, ctx context.Context, existing field.Fields) field.Fields {
+	if len(extractors) == 0 {
+		return nil
+	}
+
+	keys := make(map[string]struct{}, len(existing))
+	for _, f := range existing {
+		keys[f.Key()] = struct{}{}
+	}
+
+	ret := make(field.Fields, 0, len(extractors))
+	for _, extractor := range extractors {
+		if extractor == nil {
+			continue
+		}
+
+		for _, f := range extractor(ctx) {
+			if _, ok := keys[f.Key()]; ok {
+				continue
+			}
+			keys[f.Key()] = struct{}{}
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
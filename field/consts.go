@@ -1,7 +1,16 @@
 package field
 
+import "github.com/Alp4ka/mlogger/jsonsecurity"
+
 const (
 	KeyCaller = "caller"
 	KeyError  = "error"
 	KeySource = "source"
+
+	KeyEventID = "event_id"
+	KeyTraceID = "trace_id"
+	KeySpanID  = "span_id"
 )
+
+// RedactedValue replaces the values of sensitive fields.
+const RedactedValue = jsonsecurity.RedactedValue
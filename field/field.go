@@ -1,10 +1,13 @@
 package field
 
 import (
+	"errors"
+	"fmt"
 	"github.com/Alp4ka/mlogger/jsonsecurity"
 	"github.com/Alp4ka/mlogger/misc"
 	"log/slog"
 	"time"
+	"unicode/utf8"
 )
 
 var ContextKeyLogFields = struct{}{}
@@ -15,6 +18,27 @@ type Field struct {
 	Type Type
 
 	err error
+
+	// group stores raw nested fields of composite types (e.g. TypeStruct). Attr of such field is rebuilt from the
+	// masked copy of group on every Mask call.
+	group Fields
+	// label is a mask method applied to the value regardless of masker triggers.
+	label jsonsecurity.MaskerLabel
+	// triggerKey overrides the key used to look up masker triggers, e.g. the column of sql argument.
+	triggerKey string
+	// skipTriggers disables masking of the field by masker triggers. See SkipTriggers.
+	skipTriggers bool
+	// masked is set once the field is masked, so it's not masked again.
+	masked bool
+	// lazy produces the value of the field on demand. See Lazy.
+	lazy *lazyValue
+	// visibility defines the destinations of the field. See LocalOnly, AlertOnly and Sensitive.
+	visibility visibility
+	// dropped is set when the field failed to be masked and must be removed according to masker failure policy.
+	dropped bool
+	// maxLen cuts the string value to the number of bytes once the field is masked, e.g. the body of HTTPRequest which
+	// has to be masked as a whole. Zero means no limit.
+	maxLen int
 }
 
 // Error returns an error field. It's used to store the error occurred while parsing, creating the field.
@@ -24,31 +48,215 @@ func (f Field) Error() error {
 
 // Masked returns masked copy of Field using provided masker.
 func (f Field) Masked(masker *jsonsecurity.Masker) Field {
-	ff := Field{Attr: f.Attr, Type: f.Type, err: f.err}
+	ff := f
 	ff.Mask(masker)
 	return ff
 }
 
+// SkipTriggers disables masking of the field by masker triggers matching its key, e.g. for `password_changed` flag
+// matching `^password` pattern trigger. Nested fields of composite fields are still matched against triggers. Masking
+// by type (e.g. TypeJSONEscapeSecure) and by explicit label is not affected.
+func SkipTriggers(f Field) Field {
+	f.skipTriggers = true
+	return f
+}
+
 func (f Field) Key() string {
 	return f.Attr.Key
 }
 
+// Value returns the value of the field. Lazy values are evaluated and slog.LogValuer values are resolved.
 func (f Field) Value() any {
-	return f.Attr.Value.Any()
+	if f.lazy != nil {
+		return f.lazy.get().Resolve().Any()
+	}
+	return f.Attr.Value.Resolve().Any()
+}
+
+// Resolved returns copy of Field with evaluated lazy value and resolved slog.LogValuer, so every output uses the same
+// value. Other fields are returned as is.
+func (f Field) Resolved() Field {
+	if f.lazy != nil {
+		f.Attr.Value = f.lazy.get()
+		f.lazy = nil
+	}
+
+	if f.Attr.Value.Kind() == slog.KindLogValuer {
+		f.Attr.Value = f.Attr.Value.Resolve()
+		// Members of the resolved group are masked the same way members of Struct are.
+		if f.Attr.Value.Kind() == slog.KindGroup && f.group == nil {
+			f.group = attrsToFields(f.Attr.Value.Group())
+		}
+	}
+
+	return f
 }
 
-// Mask masks current Field using provided masker. In case the field has the type different to TypeJSONEscapeSecure -
-// it will not make any changes.
+// Mask masks current Field using provided masker. Fields with explicit mask label, fields which keys match masker
+// triggers, composite fields (e.g. TypeStruct), TypeJSONEscapeSecure, TypeXMLSecure and TypeFormSecure fields,
+// TypeAny fields (when enabled in masker config) and TypeString/TypeError fields (when redaction is enabled in masker
+// config) are affected, the others stay unchanged. Fields are masked once, masked copies are not masked again. Nil
+// masker masks the fields with explicit global labels only.
 func (f *Field) Mask(masker *jsonsecurity.Masker) {
-	if f.Type != TypeJSONEscapeSecure {
+	if f.masked {
 		return
 	}
 
-	masked, err := masker.Mask(f.Attr.Value.String())
+	f.mask(masker, nil)
+	f.masked = true
+}
+
+// mask masks the field. trigger is inherited from the parent group matched by masker triggers, it's applied to every
+// nested field.
+func (f *Field) mask(masker *jsonsecurity.Masker, trigger *jsonsecurity.TriggerOpts) {
+	if trigger == nil && !f.skipTriggers {
+		if opts, ok := masker.Trigger(f.maskKey()); ok {
+			trigger = &opts
+		}
+	}
+
+	switch {
+	case f.label != "":
+		f.maskLabel(masker)
+	case f.group != nil && trigger != nil && !trigger.ShouldAppear:
+		f.maskTrigger(masker, *trigger)
+	case f.group != nil:
+		f.maskGroup(masker, trigger)
+	case trigger != nil:
+		f.maskTrigger(masker, *trigger)
+	case masker == nil && (f.Type == TypeJSONEscapeSecure || f.Type == TypeXMLSecure || f.Type == TypeFormSecure):
+		// Secure values cannot be masked without config, so they fail closed.
+		f.fail(masker, f.Attr.Value.String(), errors.New("masker is not set"))
+	case masker == nil:
+		// Nil masker has no config, so only explicit labels (which fall back to global ones) apply.
+	case f.Type == TypeJSONEscapeSecure:
+		f.maskEncoded(masker, masker.Mask)
+	case f.Type == TypeXMLSecure:
+		f.maskEncoded(masker, masker.MaskXML)
+	case f.Type == TypeFormSecure:
+		f.maskEncoded(masker, masker.MaskQuery)
+	case f.Type == TypeAny && masker.ShouldMaskAny():
+		f.maskAny(masker)
+	case (f.Type == TypeString || f.Type == TypeError) && masker.ShouldRedact():
+		f.redact(masker)
+	}
+
+	f.truncate()
+}
+
+// truncate cuts the string value of the field to maxLen bytes keeping it valid UTF-8.
+func (f *Field) truncate() {
+	value, ok := f.Attr.Value.Any().(string)
+	if f.maxLen <= 0 || !ok || len(value) <= f.maxLen {
+		return
+	}
+
+	cut := f.maxLen
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	f.Attr.Value = slog.StringValue(value[:cut])
+}
+
+// maskKey returns the key used to look up masker triggers.
+func (f *Field) maskKey() string {
+	if f.triggerKey != "" {
+		return f.triggerKey
+	}
+	return f.Attr.Key
+}
+
+// redact masks PII found in the text of the field.
+func (f *Field) redact(masker *jsonsecurity.Masker) {
+	if f.Attr.Value.Kind() != slog.KindString {
+		return
+	}
+
+	f.Attr.Value = slog.StringValue(masker.Redact(f.Attr.Value.String()))
+}
+
+// maskAny masks the value of field.Any marshalling it to json. Values resolved to groups are not affected.
+func (f *Field) maskAny(masker *jsonsecurity.Masker) {
+	if f.Attr.Value.Kind() != slog.KindAny {
+		return
+	}
+
+	masked, err := masker.MaskValue(f.Attr.Value.Any())
+	if err == nil {
+		f.Attr.Value = slog.AnyValue(masked)
+	} else {
+		f.fail(masker, fmt.Sprintf("%+v", f.Attr.Value.Any()), err)
+	}
+}
+
+// maskEncoded masks encoded document (json, xml, etc.) stored in the field with the mask function of masker.
+func (f *Field) maskEncoded(masker *jsonsecurity.Masker, mask func(string) (string, error)) {
+	raw := f.Attr.Value.String()
+
+	masked, err := mask(raw)
 	if err == nil {
 		f.Attr.Value = slog.StringValue(masked)
 	} else {
-		f.err = err
+		f.fail(masker, raw, err)
+	}
+}
+
+// maskLabel masks the value using mask method stored in label.
+func (f *Field) maskLabel(masker *jsonsecurity.Masker) {
+	ret, err := masker.MaskWith(f.label, f.Attr.Key, f.Attr.Value.Any())
+	if err == nil {
+		f.Attr.Value = slog.AnyValue(ret.Value)
+	} else {
+		f.fail(masker, f.Attr.Value.String(), err)
+	}
+}
+
+// maskTrigger masks the value using mask method of the trigger.
+func (f *Field) maskTrigger(masker *jsonsecurity.Masker, trigger jsonsecurity.TriggerOpts) {
+	ret, ok, err := masker.MaskTrigger(trigger, f.maskKey(), f.Attr.Value.Any())
+	switch {
+	case err != nil:
+		f.fail(masker, f.Attr.Value.String(), err)
+	case !ok:
+		f.dropped = true
+	default:
+		f.Attr.Value = slog.AnyValue(ret.Value)
+	}
+}
+
+// fail applies masker failure policy to the field which failed to be masked. The raw value never stays in the field:
+// it's either replaced with the fallback provided by masker or the field is marked as dropped.
+func (f *Field) fail(masker *jsonsecurity.Masker, raw string, err error) {
+	f.err = err
+
+	fallback, ok := masker.Fallback(raw)
+	if !ok {
+		f.dropped = true
+		fallback = ""
+	}
+	f.Attr.Value = slog.StringValue(fallback)
+}
+
+// maskGroup rebuilds Attr of the composite field from the masked copy of nested fields. Errors of nested fields are
+// joined into the error of current field. Nested fields are masked with the trigger of the group if any.
+func (f *Field) maskGroup(masker *jsonsecurity.Masker, trigger *jsonsecurity.TriggerOpts) {
+	attrs := make([]slog.Attr, 0, len(f.group))
+	errs := make([]error, 0)
+
+	for _, nested := range f.group {
+		masked := nested
+		masked.mask(masker, trigger)
+		if masked.err != nil {
+			errs = append(errs, masked.err)
+		}
+		if !masked.dropped {
+			attrs = append(attrs, masked.Attr)
+		}
+	}
+
+	f.Attr.Value = slog.GroupValue(attrs...)
+	if len(errs) != 0 {
+		f.err = errors.Join(errs...)
 	}
 }
 
@@ -106,11 +314,58 @@ func JSONEscapeSecure(key string, value []byte) Field {
 	return Field{Attr: slog.String(key, string(value)), Type: TypeJSONEscapeSecure, err: nil}
 }
 
+// XMLSecure used for storing xml documents (e.g. SOAP messages) with the values of elements and attributes specified in
+// config masked.
+// Example:
+//
+// Data = `<login><user id="42">john</user><password>qwerty</password></login>`
+//
+// Using PASSWORD label for "password" we will reach the next result:
+//
+// Output = `<login><user id="42">john</user><password>******</password></login>`
+func XMLSecure(key string, value []byte) Field {
+	return Field{Attr: slog.String(key, string(value)), Type: TypeXMLSecure, err: nil}
+}
+
+// FormSecure used for storing application/x-www-form-urlencoded bodies, query strings and URLs with the values of
+// parameters specified in config masked.
+// Example:
+//
+// Data = `https://example.com/login?user=john&password=qwerty`
+//
+// Using PASSWORD label for "password" we will reach the next result:
+//
+// Output = `https://example.com/login?user=john&password=******`
+func FormSecure(key string, value []byte) Field {
+	return Field{Attr: slog.String(key, string(value)), Type: TypeFormSecure, err: nil}
+}
+
 // Any used for storing values of type any.
 func Any(key string, value any) Field {
 	return Field{Attr: slog.Any(key, value), Type: TypeAny, err: nil}
 }
 
+// Lazy used for storing values which are expensive to compute. The producer is called at most once and only when the
+// record is actually emitted, its result is shared between all the outputs.
+func Lazy(key string, producer func() any) Field {
+	return Field{
+		Attr: slog.Any(key, nil),
+		Type: TypeAny,
+		err:  nil,
+		lazy: newLazyValue(func() slog.Value { return slog.AnyValue(producer()) }),
+	}
+}
+
+// LazyString the same as Lazy but for string values.
+func LazyString(key string, producer func() string) Field {
+	return Field{
+		Attr: slog.String(key, ""),
+		Type: TypeString,
+		err:  nil,
+		lazy: newLazyValue(func() slog.Value { return slog.StringValue(producer()) }),
+	}
+}
+
 // CallerFunc creates field with name of caller function (using shift specified in level argument).
 func CallerFunc(key string, level ...int) Field {
 	const (
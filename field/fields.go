@@ -29,10 +29,16 @@ func (fields Fields) Prepare(masker *jsonsecurity.Masker) Fields {
 	// Go through fields slice and append the same field with suffix _FAIL when not nil err stored in Field structure.
 	// Also prepares other fields basing on their types.
 	for _, field := range fields {
-		ret = append(ret, field.Masked(masker))
+		field = field.Resolved().Masked(masker)
+		if !field.dropped {
+			ret = append(ret, field)
+		}
+
 		fieldErr := field.Error()
 		if fieldErr != nil {
-			ret = append(ret, ErrorNamed(buildErrorField(field), fieldErr))
+			errField := ErrorNamed(buildErrorField(field), fieldErr)
+			errField.visibility = field.visibility
+			ret = append(ret, errField)
 		}
 	}
 
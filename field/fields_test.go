@@ -0,0 +1,218 @@
+package field
+
+import (
+	"fmt"
+	"github.com/Alp4ka/mlogger/jsonsecurity"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestFields_Prepare_ShouldNotLeak_WhenJSONIsMalformed(t *testing.T) {
+	const secret = "qwerty123"
+
+	malformed := [][]byte{
+		[]byte(`{"password": "` + secret + `"`),
+		[]byte(`{"password": "` + secret),
+		[]byte(`{"a": {"b": {"c": {"password": "` + secret + `"}}}}`),
+		[]byte(`password=` + secret),
+	}
+
+	policies := []jsonsecurity.FailurePolicy{
+		jsonsecurity.FailurePolicyRedact,
+		jsonsecurity.FailurePolicyDrop,
+		jsonsecurity.FailurePolicyBestEffort,
+	}
+
+	for _, policy := range policies {
+		masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{
+			MaxDepth:      2,
+			FailurePolicy: policy,
+			Triggers: map[string]jsonsecurity.TriggerOpts{
+				"password": {CaseSensitive: false, ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+			},
+		})
+		assert.NoError(t, err)
+
+		for _, data := range malformed {
+			prepared := Fields{JSONEscapeSecure("secure", data)}.Prepare(masker)
+
+			assert.NotContains(t, fmt.Sprint(UnpackFieldsToSlogAttrs(prepared)), secret, "policy %d, data %s", policy, data)
+			assert.Equal(t, "secure_FAIL", prepared[len(prepared)-1].Key(), "policy %d, data %s", policy, data)
+		}
+	}
+}
+
+func TestFields_Prepare_ShouldNotPanic_WhenMaskerIsNil(t *testing.T) {
+	fields := Fields{
+		String("a", "b"),
+		Any("any", map[string]string{"password": "qwerty"}),
+		JSONEscapeSecure("secure", []byte(`{"password":"qwerty"}`)),
+		Struct("user", testStructUser{Login: "john", Email: "john.doe@example.com"}),
+	}
+
+	var prepared Fields
+	assert.NotPanics(t, func() { prepared = fields.Prepare(nil) })
+
+	attrs := fmt.Sprint(UnpackFieldsToSlogAttrs(prepared))
+	assert.Contains(t, attrs, "a=b")
+	assert.Contains(t, attrs, "secure="+RedactedValue)
+	assert.Contains(t, attrs, "secure_FAIL=masker is not set")
+	assert.NotContains(t, attrs, `secure={"password":"qwerty"}`)
+	assert.Contains(t, attrs, "login=john")
+	assert.Contains(t, attrs, "email=j*******@example.com")
+}
+
+func TestFields_Prepare_ShouldNotReportValue_WhenMaskingFails(t *testing.T) {
+	masker := newTestMasker()
+
+	prepared := Fields{XMLSecure("body", []byte(`<login user="a"password="hunter2"/>`))}.Prepare(masker)
+
+	assert.Len(t, prepared, 2)
+	assert.Equal(t, RedactedValue, prepared[0].Attr.Value.String())
+	assert.Equal(t, "body_FAIL", prepared[1].Key())
+	assert.NotContains(t, prepared[1].Attr.Value.String(), "hunter2")
+}
+
+func TestFields_Prepare_ShouldDropField_WhenPolicyIsDrop(t *testing.T) {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{FailurePolicy: jsonsecurity.FailurePolicyDrop})
+	assert.NoError(t, err)
+
+	prepared := Fields{JSONEscapeSecure("secure", []byte(`{`))}.Prepare(masker)
+
+	assert.Len(t, prepared, 1)
+	assert.Equal(t, "secure_FAIL", prepared[0].Key())
+}
+
+func TestFields_Prepare_ShouldMaskTriggeredKeys_WhenFieldIsOfAnyType(t *testing.T) {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{
+		MaxDepth: 5,
+		Triggers: map[string]jsonsecurity.TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+			"pin":      {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+			"card":     {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelCardNumber},
+			"secret":   {ShouldAppear: false},
+		},
+	})
+	assert.NoError(t, err)
+
+	type card struct {
+		Number string `mlog:"number"`
+		Holder string `mlog:"holder"`
+	}
+	type user struct {
+		Login    string `mlog:"login"`
+		Password string `mlog:"password"`
+	}
+
+	prepared := Fields{
+		String("password", "qwerty"),
+		Int("pin", 1234),
+		Any("card", "4111111111111111"),
+		String("secret", "value"),
+		Struct("user", user{Login: "john", Password: "qwerty"}),
+		Struct("card", card{Number: "4111111111111111", Holder: "JOHN DOE"}),
+		SkipTriggers(String("password", "changed")),
+		String("login", "john"),
+	}.Prepare(masker)
+
+	attrs := fmt.Sprint(UnpackFieldsToSlogAttrs(prepared))
+	assert.Equal(
+		t,
+		"[password=****** pin=**** card=411111******1111 user=[login=john password=******] "+
+			"card=[number=411111******1111 holder=********] password=changed login=john]",
+		attrs,
+	)
+}
+
+func TestFields_Prepare_ShouldNotMaskTwice(t *testing.T) {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{
+		Triggers: map[string]jsonsecurity.TriggerOpts{
+			"card": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelCardNumber},
+		},
+	})
+	assert.NoError(t, err)
+
+	prepared := Fields{String("card", "4111111111111111")}.Prepare(masker)
+	prepared = prepared.Prepare(masker)
+
+	assert.Equal(t, "411111******1111", prepared[0].Value())
+}
+
+func TestFields_Prepare_ShouldMaskXMLAndFormFields(t *testing.T) {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{
+		MaxDepth: 5,
+		Triggers: map[string]jsonsecurity.TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+		},
+	})
+	assert.NoError(t, err)
+
+	prepared := Fields{
+		XMLSecure("xml", []byte(`<login><user>john</user><password>qwerty</password></login>`)),
+		FormSecure("form", []byte(`user=john&password=qwerty`)),
+		FormSecure("url", []byte(`https://example.com/login?user=john&password=qwerty`)),
+		XMLSecure("broken", []byte(`<password>qwerty`)),
+	}.Prepare(masker)
+
+	assert.Equal(t, `<login><user>john</user><password>******</password></login>`, prepared[0].Value())
+	assert.Equal(t, `user=john&password=******`, prepared[1].Value())
+	assert.Equal(t, `https://example.com/login?user=john&password=******`, prepared[2].Value())
+	assert.Equal(t, jsonsecurity.RedactedValue, prepared[3].Value())
+	assert.Equal(t, "broken_FAIL", prepared[4].Key())
+}
+
+type testLogValuer struct {
+	login    string
+	password string
+}
+
+func (v testLogValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("login", v.login), slog.String("password", v.password))
+}
+
+type testCredentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	token    string
+}
+
+func TestFields_Prepare_ShouldMaskAnyValues(t *testing.T) {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{
+		MaxDepth: 5,
+		MaskAny:  true,
+		Triggers: map[string]jsonsecurity.TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+		},
+	})
+	assert.NoError(t, err)
+
+	prepared := Fields{
+		Any("creds", testCredentials{Login: "john", Password: "qwerty", token: "t"}),
+		Any("error", fmt.Errorf("login failed")),
+		Any("valuer", testLogValuer{login: "john", password: "qwerty"}),
+		Any("list", []any{map[string]any{"password": "abc"}}),
+	}.Prepare(masker)
+
+	assert.Equal(
+		t,
+		"[creds=map[login:john password:******] error=login failed valuer=[login=john password=******] "+
+			"list=[map[password:***]]]",
+		fmt.Sprint(UnpackFieldsToSlogAttrs(prepared)),
+	)
+}
+
+func TestFields_Prepare_ShouldKeepAnyValues_WhenMaskAnyIsDisabled(t *testing.T) {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{
+		MaxDepth: 5,
+		Triggers: map[string]jsonsecurity.TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+		},
+	})
+	assert.NoError(t, err)
+
+	creds := testCredentials{Login: "john", Password: "qwerty"}
+	prepared := Fields{Any("creds", creds)}.Prepare(masker)
+
+	assert.Equal(t, creds, prepared[0].Value())
+}
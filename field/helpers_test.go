@@ -0,0 +1,18 @@
+package field
+
+import "github.com/Alp4ka/mlogger/jsonsecurity"
+
+// newTestMasker returns masker with password trigger shared by the tests.
+func newTestMasker() *jsonsecurity.Masker {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{
+		MaxDepth: 5,
+		Triggers: map[string]jsonsecurity.TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return masker
+}
@@ -0,0 +1,205 @@
+package field
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	KeyHTTPMethod        = "method"
+	KeyHTTPURL           = "url"
+	KeyHTTPStatus        = "status"
+	KeyHTTPHeaders       = "headers"
+	KeyHTTPContentLength = "content_length"
+	KeyHTTPBody          = "body"
+	KeyHTTPBodyTruncated = "body_truncated"
+
+	// httpDefaultMaxBodySize is used when HTTPOptions.MaxBodySize is not specified.
+	httpDefaultMaxBodySize = 4 << 10
+)
+
+// _httpRedactedHeaders are always redacted regardless of HTTPOptions.
+var _httpRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// HTTPOptions configures HTTPRequest and HTTPResponse fields.
+type HTTPOptions struct {
+	// Headers to capture. All headers are captured if empty.
+	Headers []string
+	// RedactHeaders are redacted in addition to Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+	RedactHeaders []string
+	// MaxBodySize caps the logged body. Zero means default size of 4KB, negative value disables body capturing. JSON,
+	// XML and form bodies exceeding the size are redacted, since a partial document cannot be masked reliably.
+	MaxBodySize int
+}
+
+// HTTPRequest used for storing http request as a group with method, url, headers, content length and body. The body is
+// restored after reading, so the request can still be used. Userinfo password of the url is redacted, query parameters
+// of the url and form bodies are masked the same way FormSecure does, JSON and XML bodies are masked the same way
+// JSONEscapeSecure and XMLSecure are.
+func HTTPRequest(key string, r *http.Request, opts HTTPOptions) Field {
+	if r == nil {
+		return Field{Attr: slog.Any(key, nil), Type: TypeHTTP, err: nil, group: make(Fields, 0)}
+	}
+
+	group := Fields{
+		String(KeyHTTPMethod, r.Method),
+		httpURL(r.URL),
+		httpHeaders(r.Header, opts),
+		Int(KeyHTTPContentLength, r.ContentLength),
+	}
+
+	var (
+		body Fields
+		err  error
+	)
+	body, r.Body, err = httpBody(r.Body, r.Header, opts)
+	group = append(group, body...)
+
+	return Field{Attr: slog.Any(key, nil), Type: TypeHTTP, err: err, group: group}
+}
+
+// HTTPResponse used for storing http response as a group with status, headers, content length and body. Method and url
+// are taken from the request of response if any. The body is restored after reading.
+func HTTPResponse(key string, r *http.Response, opts HTTPOptions) Field {
+	if r == nil {
+		return Field{Attr: slog.Any(key, nil), Type: TypeHTTP, err: nil, group: make(Fields, 0)}
+	}
+
+	group := make(Fields, 0, 7)
+	if r.Request != nil {
+		group = append(
+			group,
+			String(KeyHTTPMethod, r.Request.Method),
+			httpURL(r.Request.URL),
+		)
+	}
+	group = append(
+		group,
+		Int(KeyHTTPStatus, r.StatusCode),
+		httpHeaders(r.Header, opts),
+		Int(KeyHTTPContentLength, r.ContentLength),
+	)
+
+	var (
+		body Fields
+		err  error
+	)
+	body, r.Body, err = httpBody(r.Body, r.Header, opts)
+	group = append(group, body...)
+
+	return Field{Attr: slog.Any(key, nil), Type: TypeHTTP, err: err, group: group}
+}
+
+// httpURL builds url field with userinfo password redacted and query parameters masked.
+func httpURL(u *url.URL) Field {
+	if u == nil {
+		return String(KeyHTTPURL, "")
+	}
+
+	return FormSecure(KeyHTTPURL, []byte(u.Redacted()))
+}
+
+// httpHeaders builds the group of headers selected in options with sensitive ones redacted.
+func httpHeaders(header http.Header, opts HTTPOptions) Field {
+	names := make([]string, 0, len(header))
+	if len(opts.Headers) == 0 {
+		for name := range header {
+			names = append(names, name)
+		}
+	} else {
+		for _, name := range opts.Headers {
+			if _, ok := header[http.CanonicalHeaderKey(name)]; ok {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+
+	group := make(Fields, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header.Values(name), ", ")
+		if isRedactedHeader(name, opts) {
+			value = RedactedValue
+		}
+		group = append(group, String(name, value))
+	}
+
+	return Field{Attr: slog.Any(KeyHTTPHeaders, nil), Type: TypeHTTP, err: nil, group: group}
+}
+
+// isRedactedHeader reports whether the header value must be redacted.
+func isRedactedHeader(name string, opts HTTPOptions) bool {
+	for _, redacted := range _httpRedactedHeaders {
+		if strings.EqualFold(name, redacted) {
+			return true
+		}
+	}
+	for _, redacted := range opts.RedactHeaders {
+		if strings.EqualFold(name, redacted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// httpBody reads up to MaxBodySize bytes of the body and returns fields describing it along with the restored body.
+// JSON, XML and form bodies are masked as a whole and redacted if they exceed MaxBodySize, other bodies are cut.
+func httpBody(body io.ReadCloser, header http.Header, opts HTTPOptions) (Fields, io.ReadCloser, error) {
+	if body == nil || body == http.NoBody || opts.MaxBodySize < 0 {
+		return nil, body, nil
+	}
+
+	maxSize := opts.MaxBodySize
+	if maxSize == 0 {
+		maxSize = httpDefaultMaxBodySize
+	}
+
+	var secure func(key string, value []byte) Field
+	switch contentType := strings.ToLower(header.Get("Content-Type")); {
+	case strings.Contains(contentType, "json"):
+		secure = JSONEscapeSecure
+	case strings.Contains(contentType, "xml"):
+		secure = XMLSecure
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		secure = FormSecure
+	}
+
+	// Read one more byte to find out whether the body is truncated.
+	data, err := io.ReadAll(io.LimitReader(body, int64(maxSize)+1))
+	restored := &httpReadCloser{Reader: io.MultiReader(bytes.NewReader(data), body), Closer: body}
+	if err != nil {
+		return nil, restored, err
+	}
+
+	if len(data) == 0 {
+		return nil, restored, nil
+	}
+
+	truncated := len(data) > maxSize
+
+	var bodyField Field
+	switch {
+	case secure != nil && truncated:
+		bodyField = String(KeyHTTPBody, RedactedValue)
+	case secure != nil:
+		bodyField = secure(KeyHTTPBody, data)
+		bodyField.maxLen = maxSize
+	default:
+		bodyField = String(KeyHTTPBody, string(data))
+		bodyField.maxLen = maxSize
+	}
+
+	return Fields{bodyField, Bool(KeyHTTPBodyTruncated, truncated)}, restored, nil
+}
+
+// httpReadCloser restores already read part of the body keeping original Close method.
+type httpReadCloser struct {
+	io.Reader
+	io.Closer
+}
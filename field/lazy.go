@@ -0,0 +1,28 @@
+package field
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// lazyValue evaluates producer once and caches the result. It's shared between copies of the Field, so the value
+// computed for one output is reused by the others.
+type lazyValue struct {
+	once     sync.Once
+	producer func() slog.Value
+	value    slog.Value
+}
+
+func newLazyValue(producer func() slog.Value) *lazyValue {
+	return &lazyValue{producer: producer}
+}
+
+// get returns the value calling producer on first use.
+func (l *lazyValue) get() slog.Value {
+	l.once.Do(func() {
+		l.value = l.producer()
+		l.producer = nil
+	})
+
+	return l.value
+}
@@ -11,3 +11,33 @@ func UnpackFieldsToSlogAttrs(fields Fields) []slog.Attr {
 
 	return attrs
 }
+
+// attrsToFields converts attrs of resolved slog group into fields, so they can be masked. Nested groups are converted
+// recursively.
+func attrsToFields(attrs []slog.Attr) Fields {
+	fields := make(Fields, 0, len(attrs))
+
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+
+		f := Field{Attr: attr, Type: TypeAny, err: nil}
+		switch attr.Value.Kind() {
+		case slog.KindGroup:
+			f.group = attrsToFields(attr.Value.Group())
+		case slog.KindString:
+			f.Type = TypeString
+		case slog.KindInt64:
+			f.Type = TypeInt
+		case slog.KindUint64:
+			f.Type = TypeUint
+		case slog.KindFloat64:
+			f.Type = TypeFloat
+		case slog.KindBool:
+			f.Type = TypeBool
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
@@ -0,0 +1,423 @@
+package field
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// _sqlDollarQuoteRegex matches opening delimiter of dollar-quoted string, e.g. `$$` or `$body$`.
+var _sqlDollarQuoteRegex = regexp.MustCompile(`^\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+const (
+	KeySQLQuery     = "query"
+	KeySQLArgsCount = "args_count"
+	KeySQLArgs      = "args"
+
+	// sqlMaxInListItems is a number of items kept in long `IN (...)` lists, the rest is collapsed.
+	sqlMaxInListItems = 3
+)
+
+// SQL used for storing sql queries with arguments. The query is normalized: comments are removed, whitespaces are
+// collapsed, string (including dollar-quoted) and numeric literals are replaced with `?` and long `IN (...)` lists are
+// shortened along with their arguments. Double-quoted strings are identifiers unless they're compared to a column or
+// listed in `VALUES` or `IN (...)`, where they're treated as literals the way MySQL does.
+//
+// Arguments are masked by the column they're bound to (e.g. `password = ?`, `password = @p1`,
+// `INSERT INTO t (password) VALUES (?)`) if it matches masker triggers, named arguments which are not bound to a column
+// are masked by their name. Positional arguments are keyed by their column or by their ordinal number, named ones are
+// keyed by their name. Repeated keys are suffixed with the ordinal number, e.g. `id`, `id_2`.
+func SQL(key string, query string, args ...any) Field {
+	stmt := parseSQL(query)
+
+	group := make(Fields, 0, len(args))
+	seen := make(map[string]bool, len(args))
+	for i, arg := range args {
+		var argKey, placeholder string
+
+		ordinal := strconv.Itoa(i + 1)
+		if named, ok := arg.(sql.NamedArg); ok {
+			argKey, placeholder, arg = named.Name, named.Name, named.Value
+		} else {
+			argKey, placeholder = ordinal, ordinal
+		}
+
+		if stmt.collapsed[placeholder] {
+			continue
+		}
+
+		// Named arguments keep their name as the key, but both kinds are masked by the column they're bound to.
+		triggerKey := argKey
+		if column, ok := stmt.columns[placeholder]; ok {
+			triggerKey = column
+			if argKey == ordinal {
+				argKey = column
+			}
+		}
+
+		if seen[argKey] {
+			argKey += "_" + ordinal
+		}
+		seen[argKey] = true
+
+		group = append(group, Field{Attr: slog.Any(argKey, arg), Type: TypeAny, err: nil, triggerKey: triggerKey})
+	}
+
+	return Field{
+		Attr: slog.Any(key, nil),
+		Type: TypeSQL,
+		err:  nil,
+		group: Fields{
+			String(KeySQLQuery, stmt.query),
+			Int(KeySQLArgsCount, len(args)),
+			{Attr: slog.Any(KeySQLArgs, nil), Type: TypeSQL, err: nil, group: group},
+		},
+	}
+}
+
+type sqlTokenKind uint8
+
+const (
+	sqlTokenWord sqlTokenKind = iota
+	sqlTokenString
+	sqlTokenPlaceholder
+	sqlTokenPunct
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+	// space reports whether the token was preceded by whitespace or comment.
+	space bool
+}
+
+// sqlStatement stores the result of parseSQL.
+type sqlStatement struct {
+	// query is a normalized query.
+	query string
+	// columns maps placeholder ordinal (or name of named placeholder) to the column it's bound to.
+	columns map[string]string
+	// collapsed stores placeholders removed from the query while shortening `IN (...)` lists.
+	collapsed map[string]bool
+}
+
+// parseSQL normalizes the query and binds its placeholders to columns.
+func parseSQL(query string) sqlStatement {
+	tokens := tokenizeSQL(query)
+	stmt := sqlStatement{columns: make(map[string]string), collapsed: make(map[string]bool)}
+
+	var (
+		b          strings.Builder
+		ordinal    int
+		depth      int
+		inColumn   string // column of the current `IN (...)` list
+		inDepth    = -1   // depth of the current `IN (...)` list
+		inCut      = -1   // index of the token from which the current `IN (...)` list is collapsed
+		inCutItems int    // number of items collapsed in the current `IN (...)` list
+		inserting  []string
+		values     bool // whether `VALUES` tuples are being parsed
+		valuesPos  = -1 // position of the placeholder within `VALUES (...)` tuple
+	)
+
+	for i, token := range tokens {
+		closing := token.kind == sqlTokenPunct && token.text == ")"
+
+		if inCut != -1 && i >= inCut && !(closing && depth == inDepth+1) {
+			if token.kind == sqlTokenPlaceholder {
+				stmt.collapsed[placeholderName(token.text, &ordinal)] = true
+			}
+			continue
+		}
+
+		if closing && inCut != -1 && depth == inDepth+1 {
+			b.WriteString(fmt.Sprintf(" /* +%d */", inCutItems))
+		}
+
+		if token.space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+
+		switch token.kind {
+		case sqlTokenPunct:
+			switch token.text {
+			case "(":
+				switch {
+				case isSQLWord(tokens, i-1, "IN"):
+					inColumn, inDepth = sqlColumnBefore(tokens, i-1), depth
+					inCut, inCutItems = sqlInListCut(tokens, i)
+				case values && depth == 0:
+					valuesPos = 0
+				case inserting == nil && sqlInsertColumns(tokens, i):
+					inserting = make([]string, 0)
+				}
+				depth++
+			case ")":
+				depth--
+				if depth == inDepth {
+					inColumn, inDepth, inCut = "", -1, -1
+				}
+				if depth == 0 {
+					valuesPos = -1
+				}
+			case ",":
+				if valuesPos != -1 && depth == 1 {
+					valuesPos++
+				}
+			}
+		case sqlTokenWord:
+			switch {
+			case depth == 0:
+				values = strings.EqualFold(token.text, "VALUES")
+			case inserting != nil && !values && depth == 1:
+				inserting = append(inserting, sqlColumnName(token.text))
+			}
+		case sqlTokenPlaceholder:
+			name := placeholderName(token.text, &ordinal)
+			switch {
+			case inColumn != "":
+				stmt.columns[name] = inColumn
+			case valuesPos != -1 && valuesPos < len(inserting):
+				stmt.columns[name] = inserting[valuesPos]
+			case isSQLComparison(tokens, i-1):
+				if column := sqlColumnBefore(tokens, i-1); column != "" {
+					stmt.columns[name] = column
+				}
+			}
+		}
+
+		quotedValue := isSQLQuoted(token) && (isSQLComparison(tokens, i-1) || valuesPos != -1 || inDepth != -1)
+		if token.kind == sqlTokenString || isSQLNumber(token) || quotedValue {
+			// Literals may contain secrets inlined into the query, so they're never logged.
+			b.WriteByte('?')
+		} else {
+			b.WriteString(token.text)
+		}
+	}
+
+	stmt.query = b.String()
+	return stmt
+}
+
+// tokenizeSQL splits the query into tokens skipping whitespaces and comments.
+func tokenizeSQL(query string) []sqlToken {
+	tokens := make([]sqlToken, 0, len(query)/4)
+	space := false
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			space = true
+			continue
+		case strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end != -1 {
+				i += end + 1
+			} else {
+				i = len(query)
+			}
+			space = true
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end != -1 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+			space = true
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			i = sqlQuotedEnd(query, i)
+			kind := sqlTokenWord
+			if c == '\'' {
+				kind = sqlTokenString
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: query[start:i], space: space})
+		case c == '$' && _sqlDollarQuoteRegex.MatchString(query[i:]):
+			tag := _sqlDollarQuoteRegex.FindString(query[i:])
+			if end := strings.Index(query[i+len(tag):], tag); end != -1 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(query)
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenString, text: query[start:i], space: space})
+		case c == '?' || (c == '$' && i+1 < len(query) && isDigit(query[i+1])):
+			i++
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenPlaceholder, text: query[start:i], space: space})
+		case (c == ':' || c == '@') && i+1 < len(query) && isWordStart(query[i+1]) &&
+			(i == 0 || query[i-1] != ':'):
+			i++
+			for i < len(query) && isWordChar(query[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenPlaceholder, text: query[start:i], space: space})
+		case isWordChar(c):
+			for i < len(query) && (isWordChar(query[i]) || query[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenWord, text: query[start:i], space: space})
+		default:
+			i++
+			if i < len(query) {
+				switch query[start : i+1] {
+				case "<>", "!=", "<=", ">=", "||", "::":
+					i++
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenPunct, text: query[start:i], space: space})
+		}
+
+		space = false
+	}
+
+	return tokens
+}
+
+// sqlQuotedEnd returns the index following the quoted literal started at i. Doubled quotes and characters preceded by
+// backslash are treated as escaped.
+func sqlQuotedEnd(query string, i int) int {
+	quote := query[i]
+	for i++; i < len(query); i++ {
+		if query[i] == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+
+	return len(query)
+}
+
+// sqlInListCut returns the index of the token from which `IN (...)` list opened at i must be collapsed and the number
+// of collapsed items. Lists with nested parentheses (e.g. subqueries) are not collapsed.
+func sqlInListCut(tokens []sqlToken, i int) (int, int) {
+	items, cut := 1, -1
+	for j := i + 1; j < len(tokens); j++ {
+		if tokens[j].kind != sqlTokenPunct {
+			continue
+		}
+
+		switch tokens[j].text {
+		case "(":
+			return -1, 0
+		case ",":
+			if items == sqlMaxInListItems {
+				cut = j
+			}
+			items++
+		case ")":
+			if items <= sqlMaxInListItems {
+				return -1, 0
+			}
+			return cut, items - sqlMaxInListItems
+		}
+	}
+
+	return -1, 0
+}
+
+// sqlInsertColumns reports whether the parenthesis at i opens column list of `INSERT INTO table (...)`.
+func sqlInsertColumns(tokens []sqlToken, i int) bool {
+	return i >= 3 &&
+		tokens[i-1].kind == sqlTokenWord &&
+		isSQLWord(tokens, i-2, "INTO") &&
+		isSQLWord(tokens, i-3, "INSERT")
+}
+
+// sqlColumnBefore returns the column name preceding the operator at i skipping NOT keyword and type casts.
+func sqlColumnBefore(tokens []sqlToken, i int) string {
+	i--
+	if isSQLWord(tokens, i, "NOT") {
+		i--
+	}
+	if i >= 2 && tokens[i-1].kind == sqlTokenPunct && tokens[i-1].text == "::" {
+		i -= 2
+	}
+	if i < 0 || tokens[i].kind != sqlTokenWord {
+		return ""
+	}
+
+	return sqlColumnName(tokens[i].text)
+}
+
+// sqlColumnName strips table qualifier and quotes from column name.
+func sqlColumnName(name string) string {
+	if idx := strings.LastIndexByte(name, '.'); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	return strings.Trim(name, "\"`")
+}
+
+// isSQLComparison reports whether the token at i is a comparison operator.
+func isSQLComparison(tokens []sqlToken, i int) bool {
+	if i < 0 {
+		return false
+	}
+
+	switch tokens[i].kind {
+	case sqlTokenPunct:
+		switch tokens[i].text {
+		case "=", "<>", "!=", "<", ">", "<=", ">=":
+			return true
+		}
+	case sqlTokenWord:
+		return strings.EqualFold(tokens[i].text, "LIKE") || strings.EqualFold(tokens[i].text, "ILIKE")
+	}
+
+	return false
+}
+
+// isSQLQuoted reports whether the token is a double-quoted string.
+func isSQLQuoted(token sqlToken) bool {
+	return token.kind == sqlTokenWord && token.text[0] == '"'
+}
+
+// isSQLNumber reports whether the token is a numeric literal.
+func isSQLNumber(token sqlToken) bool {
+	return token.kind == sqlTokenWord && isDigit(token.text[0])
+}
+
+// isSQLWord reports whether the token at i is the specified keyword.
+func isSQLWord(tokens []sqlToken, i int, word string) bool {
+	return i >= 0 && i < len(tokens) && tokens[i].kind == sqlTokenWord && strings.EqualFold(tokens[i].text, word)
+}
+
+// placeholderName returns the name of placeholder used for binding arguments. Anonymous placeholders are numbered
+// using ordinal counter.
+func placeholderName(placeholder string, ordinal *int) string {
+	switch {
+	case placeholder == "?":
+		*ordinal++
+		return strconv.Itoa(*ordinal)
+	default:
+		return placeholder[1:]
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isWordChar(c byte) bool {
+	return isWordStart(c) || isDigit(c) || c == '$'
+}
@@ -0,0 +1,179 @@
+package field
+
+import (
+	"database/sql"
+	"github.com/Alp4ka/mlogger/jsonsecurity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseSQL(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      string
+		columns   map[string]string
+		collapsed map[string]bool
+	}{
+		{
+			name:    "comparison",
+			query:   "SELECT *\n  FROM users u -- comment\n WHERE u.email = ? AND  password=?",
+			want:    "SELECT * FROM users u WHERE u.email = ? AND password=?",
+			columns: map[string]string{"1": "email", "2": "password"},
+		},
+		{
+			name:    "insert",
+			query:   `INSERT INTO users ("login", card) VALUES ($1, $2), ($3, $4)`,
+			want:    `INSERT INTO users ("login", card) VALUES ($1, $2), ($3, $4)`,
+			columns: map[string]string{"1": "login", "2": "card", "3": "login", "4": "card"},
+		},
+		{
+			name:    "named",
+			query:   "UPDATE users SET password = :password /* secret */ WHERE id = @id AND created::date > ?",
+			want:    "UPDATE users SET password = :password WHERE id = @id AND created::date > ?",
+			columns: map[string]string{"password": "password", "id": "id", "1": "created"},
+		},
+		{
+			name:    "inline literals",
+			query:   "UPDATE users SET password = 'hunter''2', pin = 1234, updated = ? WHERE id = 42 LIMIT 1",
+			want:    "UPDATE users SET password = ?, pin = ?, updated = ? WHERE id = ? LIMIT ?",
+			columns: map[string]string{"1": "updated"},
+		},
+		{
+			name:      "long in list",
+			query:     "SELECT * FROM cards WHERE card NOT IN (?, ?, ?, ?, ?) AND id = ?",
+			want:      "SELECT * FROM cards WHERE card NOT IN (?, ?, ? /* +2 */) AND id = ?",
+			columns:   map[string]string{"1": "card", "2": "card", "3": "card", "6": "id"},
+			collapsed: map[string]bool{"4": true, "5": true},
+		},
+		{
+			name:    "string literal",
+			query:   "SELECT '? -- not a comment' FROM t WHERE a IN (SELECT b FROM c WHERE d = ?)",
+			want:    "SELECT ? FROM t WHERE a IN (SELECT b FROM c WHERE d = ?)",
+			columns: map[string]string{"1": "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := parseSQL(tt.query)
+			assert.Equal(t, tt.want, stmt.query)
+			assert.Equal(t, tt.columns, stmt.columns)
+			if tt.collapsed == nil {
+				tt.collapsed = map[string]bool{}
+			}
+			assert.Equal(t, tt.collapsed, stmt.collapsed)
+		})
+	}
+}
+
+// sqlArgs returns masked arguments of SQL field mapped by their keys.
+func sqlArgs(f Field, masker *jsonsecurity.Masker) map[string]any {
+	prepared := Fields{f}.Prepare(masker)
+
+	ret := make(map[string]any)
+	for _, attr := range prepared[0].Attr.Value.Group() {
+		if attr.Key != KeySQLArgs {
+			continue
+		}
+		for _, arg := range attr.Value.Group() {
+			ret[arg.Key] = arg.Value.Any()
+		}
+	}
+
+	return ret
+}
+
+func TestSQL_ShouldHideInlineLiterals(t *testing.T) {
+	masker := newTestMasker()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "single-quoted",
+			query: "UPDATE users SET password = 'hunter2' WHERE login = 'john'",
+			want:  "UPDATE users SET password = ? WHERE login = ?",
+		},
+		{
+			name:  "double-quoted",
+			query: `UPDATE users SET "password" = "hunter2" WHERE login IN ("john") AND id = 1`,
+			want:  `UPDATE users SET "password" = ? WHERE login IN (?) AND id = ?`,
+		},
+		{
+			name:  "double-quoted values",
+			query: `INSERT INTO users ("login", password) VALUES ("john", "hunter2")`,
+			want:  `INSERT INTO users ("login", password) VALUES (?, ?)`,
+		},
+		{
+			name:  "backslash escape",
+			query: `UPDATE users SET password = 'pa\'ssw0rdsecret' WHERE id = ?`,
+			want:  "UPDATE users SET password = ? WHERE id = ?",
+		},
+		{
+			name:  "dollar-quoted",
+			query: "UPDATE users SET password = $$topsecret$$, pin = $p$12'34$p$ WHERE id = $1",
+			want:  "UPDATE users SET password = ?, pin = ? WHERE id = $1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepared := Fields{SQL("query", tt.query)}.Prepare(masker)
+
+			query := prepared[0].Attr.Value.Group()[0]
+			assert.Equal(t, KeySQLQuery, query.Key)
+			assert.Equal(t, tt.want, query.Value.String())
+		})
+	}
+}
+
+func TestSQL_ShouldMaskArgsByBoundColumn(t *testing.T) {
+	masker := newTestMasker()
+
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+		want  map[string]any
+	}{
+		{
+			name:  "positional",
+			query: "UPDATE users SET password = ? WHERE id = ?",
+			args:  []any{"hunter22", 42},
+			want:  map[string]any{"password": "********", "id": int64(42)},
+		},
+		{
+			name:  "numbered",
+			query: "INSERT INTO users (login, password) VALUES ($1, $2)",
+			args:  []any{"john", "hunter22"},
+			want:  map[string]any{"login": "john", "password": "********"},
+		},
+		{
+			name:  "named",
+			query: "UPDATE users SET password = @p1 WHERE id = @p2",
+			args:  []any{sql.Named("p1", "hunter22"), sql.Named("p2", 42)},
+			want:  map[string]any{"p1": "********", "p2": int64(42)},
+		},
+		{
+			name:  "named without column",
+			query: "SELECT check_password(:password)",
+			args:  []any{sql.Named("password", "hunter22")},
+			want:  map[string]any{"password": "********"},
+		},
+		{
+			name:  "unbound",
+			query: "SELECT check_password(?)",
+			args:  []any{"hunter22"},
+			want:  map[string]any{"1": "hunter22"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sqlArgs(SQL("query", tt.query, tt.args...), masker))
+		})
+	}
+}
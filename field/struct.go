@@ -0,0 +1,185 @@
+package field
+
+import (
+	"fmt"
+	"github.com/Alp4ka/mlogger/jsonsecurity"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	structTagName     = "mlog"
+	structTagSkip     = "-"
+	structTagOmit     = "omitempty"
+	structTagMaskPref = "mask="
+
+	// structMaxDepth limits nesting of structs in order to protect from pointer cycles.
+	structMaxDepth = 10
+)
+
+var (
+	_structCache sync.Map // map[reflect.Type]*structInfo
+
+	_timeType     = reflect.TypeOf(time.Time{})
+	_logValuerTyp = reflect.TypeOf((*slog.LogValuer)(nil)).Elem()
+)
+
+// structInfo describes loggable members of a struct type. It's computed once per type and cached in _structCache.
+type structInfo struct {
+	members []structMember
+}
+
+// structMember describes a single struct member parsed from `mlog` tag.
+type structMember struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	label     jsonsecurity.MaskerLabel
+}
+
+// Struct used for storing structs as nested groups. Exported members are logged using their names unless `mlog` tag
+// says otherwise. Tag format is the same to encoding/json one with additional mask option:
+//
+//	type Request struct {
+//		Email    string `mlog:"email,mask=EMAIL"`
+//		Password string `mlog:"-"`
+//		Comment  string `mlog:",omitempty"`
+//	}
+//
+// Members with mask option are masked using jsonsecurity labels when the field is being prepared.
+func Struct(key string, value any) Field {
+	return structField(key, reflect.ValueOf(value), 0)
+}
+
+// structField reflects over the value and builds TypeStruct field with nested members.
+func structField(key string, rv reflect.Value, depth int) Field {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return Field{Attr: slog.Any(key, nil), Type: TypeStruct, err: nil, group: make(Fields, 0)}
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return Field{Attr: slog.Any(key, nil), Type: TypeStruct, err: fmt.Errorf("not a struct: %s", rv.Kind())}
+	}
+
+	if depth >= structMaxDepth {
+		return Field{Attr: slog.Any(key, nil), Type: TypeStruct, err: fmt.Errorf("max struct depth reached: %d", depth)}
+	}
+
+	info := cachedStructInfo(rv.Type())
+	group := make(Fields, 0, len(info.members))
+
+	for _, member := range info.members {
+		mv, err := rv.FieldByIndexErr(member.index)
+		if err != nil {
+			// Embedded pointer is nil.
+			continue
+		}
+
+		if member.omitEmpty && isEmptyValue(mv) {
+			continue
+		}
+
+		var nested Field
+		if member.label == "" && isStructMember(mv) {
+			nested = structField(member.name, mv, depth+1)
+		} else {
+			nested = Field{Attr: slog.Any(member.name, mv.Interface()), Type: TypeAny, err: nil}
+		}
+		nested.label = member.label
+
+		group = append(group, nested)
+	}
+
+	return Field{Attr: slog.Any(key, nil), Type: TypeStruct, err: nil, group: group}
+}
+
+// cachedStructInfo returns structInfo for the type using _structCache.
+func cachedStructInfo(t reflect.Type) *structInfo {
+	if info, ok := _structCache.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	info, _ := _structCache.LoadOrStore(t, &structInfo{members: parseStructMembers(t, nil)})
+	return info.(*structInfo)
+}
+
+// parseStructMembers parses `mlog` tags of the struct type. Untagged embedded structs are flattened.
+func parseStructMembers(t reflect.Type, index []int) []structMember {
+	members := make([]structMember, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup(structTagName)
+		if tag == structTagSkip {
+			continue
+		}
+
+		memberIndex := make([]int, len(index)+1)
+		copy(memberIndex, index)
+		memberIndex[len(index)] = i
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				members = append(members, parseStructMembers(ft, memberIndex)...)
+				continue
+			}
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		member := structMember{name: name, index: memberIndex}
+		if member.name == "" {
+			member.name = sf.Name
+		}
+
+		for _, opt := range strings.Split(opts, ",") {
+			switch {
+			case opt == structTagOmit:
+				member.omitEmpty = true
+			case strings.HasPrefix(opt, structTagMaskPref):
+				member.label = jsonsecurity.MaskerLabel(strings.TrimPrefix(opt, structTagMaskPref))
+			}
+		}
+
+		members = append(members, member)
+	}
+
+	return members
+}
+
+// isStructMember reports whether the member should be logged as nested group.
+func isStructMember(rv reflect.Value) bool {
+	t := rv.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct &&
+		t != _timeType &&
+		!rv.Type().Implements(_logValuerTyp) &&
+		!reflect.PointerTo(t).Implements(_logValuerTyp)
+}
+
+// isEmptyValue reports whether the value is empty in terms of omitempty option.
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
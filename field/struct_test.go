@@ -0,0 +1,103 @@
+package field
+
+import (
+	"fmt"
+	"github.com/Alp4ka/mlogger/jsonsecurity"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type testStructAddress struct {
+	City string `mlog:"city"`
+	Zip  string `mlog:"zip,omitempty"`
+}
+
+type testStructBase struct {
+	ID int `mlog:"id"`
+}
+
+type testStructUser struct {
+	testStructBase
+	Login    string             `mlog:"login"`
+	Password string             `mlog:"-"`
+	Email    string             `mlog:"email,mask=EMAIL"`
+	Card     string             `mlog:"card,omitempty,mask=CARD_NUMBER"`
+	Comment  string             `mlog:",omitempty"`
+	Tags     []string           `mlog:"tags,omitempty"`
+	Address  testStructAddress  `mlog:"address"`
+	Previous *testStructAddress `mlog:"previous"`
+	Untagged string
+	internal string
+}
+
+func TestStruct_ShouldApplyTagOptions(t *testing.T) {
+	masker, err := jsonsecurity.NewMasker(jsonsecurity.Config{})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			name: "all members",
+			value: testStructUser{
+				testStructBase: testStructBase{ID: 7},
+				Login:          "john",
+				Password:       "qwerty",
+				Email:          "john@example.com",
+				Card:           "4111111111111111",
+				Comment:        "vip",
+				Tags:           []string{"a"},
+				Address:        testStructAddress{City: "Moscow", Zip: "101000"},
+				Previous:       &testStructAddress{City: "Kazan"},
+				Untagged:       "u",
+				internal:       "i",
+			},
+			want: "[user=[id=7 login=john email=j***@example.com card=411111******1111 Comment=vip tags=[a] " +
+				"address=[city=Moscow zip=101000] previous=[city=Kazan] Untagged=u]]",
+		},
+		{
+			name:  "empty members omitted",
+			value: &testStructUser{Login: "john"},
+			want:  "[user=[id=0 login=john email= address=[city=] Untagged=]]",
+		},
+		{
+			name:  "nil pointer",
+			value: (*testStructUser)(nil),
+			want:  "[user=[]]",
+		},
+	}
+
+	for _, tt := range tests {
+		prepared := Fields{Struct("user", tt.value)}.Prepare(masker)
+		assert.Equal(t, tt.want, fmt.Sprint(UnpackFieldsToSlogAttrs(prepared)), tt.name)
+	}
+}
+
+func TestStruct_ShouldFail_WhenValueIsNotStruct(t *testing.T) {
+	f := Struct("user", 42)
+	assert.Error(t, f.err)
+}
+
+func TestStruct_ShouldCacheStructInfo(t *testing.T) {
+	rt := reflect.TypeOf(testStructAddress{})
+	_structCache.Delete(rt)
+
+	Struct("a", testStructAddress{City: "Moscow"})
+	cached, ok := _structCache.Load(rt)
+	assert.True(t, ok)
+
+	Struct("b", &testStructAddress{City: "Kazan"})
+	again, _ := _structCache.Load(rt)
+	assert.Same(t, cached, again)
+	assert.Len(t, cached.(*structInfo).members, 2)
+}
+
+func TestStruct_ShouldNotExposeValue_BeforeMasking(t *testing.T) {
+	f := Struct("user", testStructUser{Login: "john", Password: "secretpwd"})
+
+	assert.NotContains(t, f.Attr.Value.String(), "secretpwd")
+	assert.NotContains(t, fmt.Sprint(UnpackFieldsToSlogAttrs(Fields{f})), "secretpwd")
+}
@@ -28,4 +28,9 @@ const (
 	TypeAny
 	TypeCallerFunc
 	TypeTimestamp
+	TypeStruct
+	TypeHTTP
+	TypeSQL
+	TypeXMLSecure
+	TypeFormSecure
 )
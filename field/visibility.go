@@ -0,0 +1,63 @@
+package field
+
+import "log/slog"
+
+// visibility defines the destinations the field is delivered to.
+type visibility uint8
+
+const (
+	// visibilityAll delivers the field both to the local writer and contact points.
+	visibilityAll visibility = iota
+	// visibilityLocalOnly delivers the field to the local writer only.
+	visibilityLocalOnly
+	// visibilityAlertOnly delivers the field to contact points only.
+	visibilityAlertOnly
+	// visibilitySensitive delivers the field to the local writer as is and redacts it for contact points.
+	visibilitySensitive
+)
+
+// LocalOnly marks the field to be written by the local writer only. It never leaves the host.
+func LocalOnly(f Field) Field {
+	f.visibility = visibilityLocalOnly
+	return f
+}
+
+// AlertOnly marks the field to be sent to contact points only.
+func AlertOnly(f Field) Field {
+	f.visibility = visibilityAlertOnly
+	return f
+}
+
+// Sensitive marks the field to be fully redacted everywhere except the local writer.
+func Sensitive(f Field) Field {
+	f.visibility = visibilitySensitive
+	return f
+}
+
+// Local returns fields which should be written by the local writer.
+func (fields Fields) Local() Fields {
+	ret := make(Fields, 0, len(fields))
+	for _, f := range fields {
+		if f.visibility != visibilityAlertOnly {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
+
+// Alert returns fields which should be sent to contact points. Sensitive fields are redacted.
+func (fields Fields) Alert() Fields {
+	ret := make(Fields, 0, len(fields))
+	for _, f := range fields {
+		switch f.visibility {
+		case visibilityLocalOnly:
+			continue
+		case visibilitySensitive:
+			f = Field{Attr: slog.String(f.Attr.Key, RedactedValue), Type: TypeString, visibility: f.visibility}
+		}
+		ret = append(ret, f)
+	}
+
+	return ret
+}
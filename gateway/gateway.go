@@ -4,8 +4,10 @@ import (
 	"context"
 	"github.com/Alp4ka/mlogger/contactpoints"
 	"github.com/Alp4ka/mlogger/field"
+	"github.com/Alp4ka/mlogger/jsonsecurity"
 	"github.com/Alp4ka/mlogger/misc"
 	"github.com/Alp4ka/mlogger/templates"
+	"github.com/Alp4ka/mlogger/tracecontext"
 	"golang.org/x/sync/errgroup"
 	"time"
 )
@@ -13,19 +15,23 @@ import (
 type Gateway struct {
 	template *templates.Template
 	contacts []contactpoints.ContactPoint
+	masker   *jsonsecurity.Masker
 }
 
 func (g Gateway) Msg(ctx context.Context, source string, level misc.Level, msg string, fields ...field.Field) error {
-	rendered, err := g.template.Render(
-		&templates.Placeholder{
-			LogTime:          time.Now(),
-			LogLevel:         level,
-			LogSource:        source,
-			LogContextFields: field.FieldsFromContext(ctx),
-			LogFields:        fields,
-			LogMessage:       msg,
-		},
-	)
+	placeholder := &templates.Placeholder{
+		LogTime:          time.Now(),
+		LogLevel:         level,
+		LogSource:        source,
+		LogContextFields: g.contextFields(ctx),
+		LogFields:        fields,
+		LogMessage:       msg,
+	}
+	if tc, ok := tracecontext.FromContext(ctx); ok {
+		placeholder.TraceID, placeholder.SpanID = tc.TraceID.String(), tc.SpanID.String()
+	}
+
+	rendered, err := g.template.Render(placeholder)
 	if err != nil {
 		return err
 	}
@@ -43,6 +49,12 @@ func (g Gateway) Msg(ctx context.Context, source string, level misc.Level, msg s
 	return eg.Wait()
 }
 
+// contextFields returns the fields of ctx visible to alerts. They're masked the same way the fields of the record are,
+// secure fields are redacted when the gateway has no masker.
+func (g Gateway) contextFields(ctx context.Context) field.Fields {
+	return field.FieldsFromContext(ctx).Prepare(g.masker).Alert()
+}
+
 func (g Gateway) WithTemplate(tmpl *templates.Template) Gateway {
 	g.template = tmpl
 	return g
@@ -57,6 +69,12 @@ func (g Gateway) WithContactPoints(replace bool, contacts ...contactpoints.Conta
 	return g
 }
 
+// WithMasker sets the masker applied to context fields before they're rendered.
+func (g Gateway) WithMasker(masker *jsonsecurity.Masker) Gateway {
+	g.masker = masker
+	return g
+}
+
 func CreateGateway() Gateway {
 	return Gateway{
 		template: templates.DefaultTemplate(misc.DefaultMode),
@@ -0,0 +1,102 @@
+package jsonsecurity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AllowlistConfig enables default-deny mode: every leaf value is masked with the default label unless it's allowlisted.
+// Triggers keep working and take precedence over the allowlist, detectors are still applied to allowlisted values.
+// The mode applies to the documents masked by Masker (json, xml, forms and headers), null values are never masked.
+type AllowlistConfig struct {
+	Enabled bool
+	// Keys pass through unmasked. The key of the leaf is the nearest object key, so the elements of allowlisted arrays
+	// pass through too, nested objects don't. Keys are case-insensitive.
+	Keys []string
+	// Paths pass through unmasked. The syntax is the same as of Config.PathTriggers, e.g. `items[*].id`. Use recursive
+	// descent to allowlist the whole object, e.g. `meta..*`.
+	Paths []string
+	// Label masks the values which are not allowlisted. PASSWORD is used if empty.
+	Label MaskerLabel
+}
+
+// allowlist is compiled AllowlistConfig.
+type allowlist struct {
+	keys  map[string]struct{}
+	paths [][]pathSegment
+	label MaskerLabel
+}
+
+// compileAllowlist parses the paths of the config. It returns nil if the mode is disabled.
+func compileAllowlist(cfg AllowlistConfig) (*allowlist, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	a := &allowlist{
+		keys:  make(map[string]struct{}, len(cfg.Keys)),
+		paths: make([][]pathSegment, 0, len(cfg.Paths)),
+		label: cfg.Label,
+	}
+	if a.label == "" {
+		a.label = MaskerLabelPassword
+	}
+
+	for _, key := range cfg.Keys {
+		a.keys[strings.ToLower(key)] = struct{}{}
+	}
+
+	for _, expr := range cfg.Paths {
+		segments, err := parsePath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist path '%s': %+v", expr, err)
+		}
+		a.paths = append(a.paths, segments)
+	}
+
+	return a, nil
+}
+
+// allows reports whether the value located at path passes through.
+func (a *allowlist) allows(path []pathElem) bool {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].isIndex {
+			continue
+		}
+		if _, ok := a.keys[strings.ToLower(path[i].key)]; ok {
+			return true
+		}
+		break
+	}
+
+	for _, segments := range a.paths {
+		if matchPath(segments, path, false) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maskDenied masks the leaf value located at path with the default label of the allowlist. ok is false when the
+// value passes through, i.e. the mode is disabled or the value is allowlisted.
+func (m *Masker) maskDenied(path []pathElem, value interface{}) (masked interface{}, ok bool, err error) {
+	if m.allowlist == nil || value == nil || m.allowlist.allows(path) {
+		return nil, false, nil
+	}
+
+	key := ""
+	for i := len(path) - 1; i >= 0; i-- {
+		if !path[i].isIndex {
+			key = path[i].key
+			break
+		}
+	}
+
+	ret, err := m.MaskWith(m.allowlist.label, key, value)
+	if err != nil {
+		return nil, false, fmt.Errorf("fail while masking value which is not allowlisted: %+v", err)
+	}
+
+	return ret.Value, true, nil
+}
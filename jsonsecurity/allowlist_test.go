@@ -0,0 +1,94 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// withAllowlist enables allowlist mode keeping the listed keys and paths readable.
+func withAllowlist(cfg *Config) {
+	cfg.Allowlist = AllowlistConfig{
+		Enabled: true,
+		Keys:    []string{"ID", "status", "tags", "card", "Content-Type"},
+		Paths:   []string{"items[*].title", "meta..*"},
+	}
+}
+
+func TestMasker_Mask_ShouldMaskNotAllowlistedValues(t *testing.T) {
+	masker := newTestMasker(withAllowlist)
+
+	ret, err := masker.Mask(`{
+  "id": 42,
+  "status": "ok",
+  "name": "John",
+  "amount": 100.5,
+  "active": true,
+  "comment": null,
+  "tags": ["a", "b"],
+  "card": "4111111111111111",
+  "secret": "s",
+  "items": [{"id": 1, "title": "book", "price": 10}],
+  "meta": {"source": "web", "nested": {"ip": "127.0.0.1"}},
+  "partner": {"new_field": "4111111111111111"}
+}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "id": 42,
+  "status": "ok",
+  "name": "****",
+  "amount": "*****",
+  "active": "****",
+  "comment": null,
+  "tags": ["a", "b"],
+  "card": "411111******1111",
+  "items": [{"id": 1, "title": "book", "price": "**"}],
+  "meta": {"source": "web", "nested": {"ip": "127.0.0.1"}},
+  "partner": {"new_field": "****************"}
+}`, ret)
+}
+
+func TestMasker_Mask_ShouldUseAllowlistLabel(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth:  2,
+		Allowlist: AllowlistConfig{Enabled: true, Label: MaskerLabelCVV},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(`{"a": "value", "b": [1]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a": "***", "b": ["***"]}`, ret)
+
+	ret, err = masker.Mask(`"value"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `"***"`, ret)
+}
+
+func TestMasker_ShouldMaskNotAllowlistedValues_WhenDocumentIsNotJSON(t *testing.T) {
+	masker := newTestMasker(withAllowlist)
+
+	ret, err := masker.MaskXML(
+		`<order id="1" note="gift"><status>ok</status><name>John</name><card>4111111111111111</card></order>`,
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`<order id="1" note="****"><status>ok</status><name>****</name><card>411111******1111</card></order>`,
+		ret,
+	)
+
+	ret, err = masker.MaskQuery(`/orders?id=1&name=John&tags[]=a&meta[source]=web`)
+	assert.NoError(t, err)
+	assert.Equal(t, `/orders?id=1&name=****&tags[]=a&meta[source]=web`, ret)
+
+	ret, err = masker.MaskHeaders("GET / HTTP/1.1\r\nContent-Type: text/plain\r\nX-Api-Key: abc\r\n\r\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "GET / HTTP/1.1\r\nContent-Type: text/plain\r\nX-Api-Key: ***\r\n\r\n", ret)
+}
+
+func TestNewMasker_ShouldFail_WhenAllowlistIsInvalid(t *testing.T) {
+	_, err := NewMasker(Config{Allowlist: AllowlistConfig{Enabled: true, Paths: []string{"items["}}})
+	assert.Error(t, err)
+
+	_, err = NewMasker(Config{Allowlist: AllowlistConfig{Enabled: true, Label: "UNKNOWN"}})
+	assert.Error(t, err)
+}
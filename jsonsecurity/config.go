@@ -3,12 +3,55 @@ package jsonsecurity
 type Config struct {
 	MaxDepth int
 	Triggers map[string]TriggerOpts
+
+	// PathTriggers are matched against the path of the value, e.g. `clientInfo.password`, `items[*].card` or
+	// `$..secret`. Paths are anchored to the root of the document. They take precedence over Triggers.
+	PathTriggers map[string]TriggerOpts
+	// PatternTriggers are regular expressions matched against keys, e.g. `(?i)_token$`. They're checked after
+	// PathTriggers and Triggers.
+	PatternTriggers map[string]TriggerOpts
+
+	// Allowlist enables default-deny mode where every value which is not allowlisted is masked. See AllowlistConfig.
+	Allowlist AllowlistConfig
+
+	// Detection enables masking of PII recognized by value regardless of the key, e.g. card numbers sent under the
+	// `data` key. Only values not matched by triggers are scanned.
+	Detection DetectionConfig
+
+	// Embedded enables masking of json documents stored in string values. See EmbeddedConfig.
+	Embedded EmbeddedConfig
+
+	// Redaction enables masking of PII in free text, e.g. log messages and string fields.
+	Redaction RedactionConfig
+
+	// Labels available to this Masker only in addition to global ones (see RegisterLabel). They must not duplicate
+	// global labels.
+	Labels map[MaskerLabel]MaskerFunc
+	// Hash configures HASH label. See MaskerLabelHash.
+	Hash HashConfig
+	// Encrypt configures ENCRYPT label. See MaskerLabelEncrypt.
+	Encrypt EncryptConfig
+	// JWT configures JWT label. See MaskerLabelJWT.
+	JWT JWTConfig
+
+	// MaskAny enables masking of values stored with field.Any. Such values are marshalled to json and masked using
+	// Triggers.
+	MaskAny bool
+
+	// FailurePolicy defines what happens to the data that failed to be masked. Redacts the whole value by default.
+	FailurePolicy FailurePolicy
 }
 
 type TriggerOpts struct {
 	CaseSensitive bool
-	MaskMethod    MaskerLabel
-	ShouldAppear  bool
+	// MaskMethod is the label masking the value. The value is kept as is if empty.
+	MaskMethod   MaskerLabel
+	ShouldAppear bool
+
+	// Strategy overrides the default parameters of MaskMethod. See MaskStrategy.
+	Strategy *MaskStrategy
+	// Replacement is used as the value when ShouldAppear is false, e.g. "[REDACTED]". The key is removed if empty.
+	Replacement string
 
 	original string
 }
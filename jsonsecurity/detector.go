@@ -0,0 +1,295 @@
+package jsonsecurity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Detector names value-based PII detector.
+type Detector string
+
+const (
+	// DetectorCardNumber detects Luhn-valid card numbers of 12-19 digits optionally separated by spaces or dashes.
+	DetectorCardNumber Detector = "CARD_NUMBER"
+	// DetectorEmail detects email addresses including internationalized ones, e.g. иван@пример.рф.
+	DetectorEmail Detector = "EMAIL"
+	// DetectorPhoneNumber detects phone numbers in international format, e.g. +7 (916) 123-45-67.
+	DetectorPhoneNumber Detector = "PHONE_NUMBER"
+	// DetectorIBAN detects IBANs with valid check digits.
+	DetectorIBAN Detector = "IBAN"
+	// DetectorJWT detects JSON Web Tokens.
+	DetectorJWT Detector = "JWT"
+	// DetectorINN detects russian taxpayer numbers with valid check digits. Plain 10-digit numbers (e.g. unix
+	// timestamps) often have valid check digit, so it's used only when listed explicitly.
+	DetectorINN Detector = "INN"
+	// DetectorSNILS detects russian insurance account numbers with valid check number. It's used only when listed
+	// explicitly.
+	DetectorSNILS Detector = "SNILS"
+	// DetectorOGRN detects russian state registration numbers with valid check digit. It's used only when listed
+	// explicitly.
+	DetectorOGRN Detector = "OGRN"
+	// DetectorSSN detects US social security numbers written with dashes, e.g. 123-45-6789.
+	DetectorSSN Detector = "SSN"
+	// DetectorBIC detects bank identifier codes. It has no checksum, so it's used only when listed explicitly.
+	DetectorBIC Detector = "BIC"
+	// DetectorPassportRU detects series and number of russian passports. It has no checksum, so it's used only when
+	// listed explicitly.
+	DetectorPassportRU Detector = "PASSPORT_RU"
+)
+
+// DetectionConfig configures value-based PII detection. Every string and number leaf which isn't matched by triggers
+// is scanned and masked with the label of the first detector recognizing the whole value.
+type DetectionConfig struct {
+	// Enabled turns the detection on.
+	Enabled bool
+	// Detectors to use. All built-in detectors except explicit ones (see DetectorBIC, DetectorPassportRU and regional
+	// identifiers) are used if empty.
+	Detectors []Detector
+	// SkipKeys are never scanned, neither are their nested values. Keys are case-insensitive.
+	SkipKeys []string
+}
+
+// detector recognizes PII in text.
+type detector struct {
+	name  Detector
+	label MaskerLabel
+	// regex finds candidates in text.
+	regex *regexp.Regexp
+	// validate checks the candidate, e.g. verifies checksum. Optional.
+	validate func(candidate string) bool
+	// explicit detectors are prone to false positives, so they're used only when listed in config.
+	explicit bool
+}
+
+// _detectors are built-in detectors in order of priority.
+var _detectors = []detector{
+	{
+		name:     DetectorJWT,
+		label:    MaskerLabelPassword,
+		regex:    regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*`),
+		validate: isJWT,
+	},
+	{
+		name:  DetectorEmail,
+		label: MaskerLabelEmail,
+		regex: regexp.MustCompile(
+			`[\p{L}\p{M}\p{N}._%+-]+@[\p{L}\p{M}\p{N}-]+(?:\.[\p{L}\p{M}\p{N}-]+)*\.(?:\p{L}{2,}|xn--[a-z0-9-]+)`,
+		),
+	},
+	{
+		name:     DetectorIBAN,
+		label:    MaskerLabelIBAN,
+		regex:    regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		validate: isIBAN,
+	},
+	{
+		name:     DetectorINN,
+		label:    MaskerLabelINN,
+		regex:    regexp.MustCompile(`\b\d{10}(?:\d{2})?\b`),
+		validate: isINN,
+		explicit: true,
+	},
+	{
+		name:     DetectorOGRN,
+		label:    MaskerLabelOGRN,
+		regex:    regexp.MustCompile(`\b\d{13}(?:\d{2})?\b`),
+		validate: isOGRN,
+		explicit: true,
+	},
+	{
+		name:     DetectorSNILS,
+		label:    MaskerLabelSNILS,
+		regex:    regexp.MustCompile(`\b\d{3}-?\d{3}-?\d{3}[ -]?\d{2}\b`),
+		validate: isSNILS,
+		explicit: true,
+	},
+	{
+		name:     DetectorCardNumber,
+		label:    MaskerLabelCardNumber,
+		regex:    regexp.MustCompile(`\b\d(?:[ -]?\d){11,18}\b`),
+		validate: isLuhn,
+	},
+	{
+		name:     DetectorPhoneNumber,
+		label:    MaskerLabelPhoneNumber,
+		regex:    regexp.MustCompile(`\+\d(?:[ ()-]{0,2}\d){6,14}`),
+		validate: isPhoneNumber,
+	},
+	{
+		name:     DetectorSSN,
+		label:    MaskerLabelSSN,
+		regex:    regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		validate: isSSN,
+	},
+	{
+		name:     DetectorBIC,
+		label:    MaskerLabelBIC,
+		regex:    regexp.MustCompile(`\b[A-Z]{6}[A-Z0-9]{2}(?:[A-Z0-9]{3})?\b`),
+		explicit: true,
+	},
+	{
+		name:     DetectorPassportRU,
+		label:    MaskerLabelPassportRU,
+		regex:    regexp.MustCompile(`\b\d{2} ?\d{2} ?\d{6}\b`),
+		explicit: true,
+	},
+}
+
+// compileDetectors returns the detectors with specified names keeping their priority. All built-in detectors except
+// explicit ones are returned if names are empty. Unknown names result in error.
+func compileDetectors(names []Detector) ([]detector, error) {
+	if len(names) == 0 {
+		ret := make([]detector, 0, len(_detectors))
+		for _, d := range _detectors {
+			if !d.explicit {
+				ret = append(ret, d)
+			}
+		}
+		return ret, nil
+	}
+
+	enabled := make(map[Detector]bool, len(names))
+	for _, name := range names {
+		enabled[name] = true
+	}
+
+	ret := make([]detector, 0, len(names))
+	for _, d := range _detectors {
+		if enabled[d.name] {
+			ret = append(ret, d)
+			delete(enabled, d.name)
+		}
+	}
+	for name := range enabled {
+		return nil, fmt.Errorf("unknown detector '%s'", name)
+	}
+
+	return ret, nil
+}
+
+// detect returns the label of the first detector recognizing the whole text of the leaf located at path.
+func (m *Masker) detect(path []pathElem, text string) (MaskerLabel, bool) {
+	if len(m.detectors) == 0 || m.skipDetection(path) {
+		return "", false
+	}
+
+	text = strings.TrimSpace(text)
+	for _, d := range m.detectors {
+		if d.matchWhole(text) {
+			return d.label, true
+		}
+	}
+
+	return "", false
+}
+
+// skipDetection reports whether any key of the path is in DetectionConfig.SkipKeys.
+func (m *Masker) skipDetection(path []pathElem) bool {
+	for _, elem := range path {
+		if elem.isIndex {
+			continue
+		}
+		if _, ok := m.detectionSkipKeys[strings.ToLower(elem.key)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchWhole reports whether the whole text is recognized by the detector.
+func (d detector) matchWhole(text string) bool {
+	loc := d.regex.FindStringIndex(text)
+	if loc == nil || loc[0] != 0 || loc[1] != len(text) {
+		return false
+	}
+
+	return d.validate == nil || d.validate(text)
+}
+
+// isLuhn validates the number using Luhn algorithm. Non-digit characters are ignored.
+func isLuhn(number string) bool {
+	var sum, digits int
+
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+
+	return digits >= 12 && digits <= 19 && sum%10 == 0
+}
+
+// isIBAN validates IBAN check digits (ISO 13616, mod 97).
+func isIBAN(iban string) bool {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	var numeric strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			numeric.WriteRune(c)
+		case c >= 'A' && c <= 'Z':
+			numeric.WriteString(strconv.Itoa(int(c-'A') + 10))
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// isPhoneNumber checks that the number has from 10 to 15 digits as E.164 requires.
+func isPhoneNumber(phone string) bool {
+	digits := 0
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits++
+		}
+	}
+
+	return digits >= 10 && digits <= 15
+}
+
+// isJWT checks that the header of the token is a json object with alg claim.
+func isJWT(token string) bool {
+	header, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return false
+	}
+
+	var claims map[string]interface{}
+	if err = json.Unmarshal(data, &claims); err != nil {
+		return false
+	}
+
+	_, ok = claims["alg"]
+	return ok
+}
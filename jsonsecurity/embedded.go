@@ -0,0 +1,88 @@
+package jsonsecurity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// embeddedBase64MinLength is the minimal length of base64 string checked for embedded json. `{"a":1}` is 12 characters
+// long when encoded.
+const embeddedBase64MinLength = 12
+
+var _base64Regex = regexp.MustCompile(`^[A-Za-z0-9+/_-]+={0,2}$`)
+
+// _base64Encodings are checked in order, padded ones go first since raw encodings accept padded input without '='.
+var _base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.URLEncoding,
+	base64.RawStdEncoding,
+	base64.RawURLEncoding,
+}
+
+// EmbeddedConfig configures masking of json documents stored in string values, e.g. webhook payloads. Embedded
+// documents are masked with the same triggers as if they were nested values of the string's key, so both `password`
+// and `payload.password` triggers apply to `{"payload": "{\"password\": \"qwerty\"}"}`. Masked documents are encoded
+// back in their original form.
+type EmbeddedConfig struct {
+	// JSON enables masking of json objects and arrays stored as strings.
+	JSON bool
+	// Base64 enables masking of json objects and arrays encoded with base64 (standard or url-safe, padded or not).
+	Base64 bool
+}
+
+// embedded masks json document embedded into the string value located at path. It returns json string literal with
+// masked document encoded in its original form. ok is false if the value doesn't contain json document.
+func (sm *streamMasker) embedded(value string, path []pathElem, depth int) (ret []byte, ok bool, err error) {
+	cfg := sm.m.cfg.Embedded
+
+	var (
+		doc      []byte
+		encoding *base64.Encoding
+	)
+
+	switch {
+	case cfg.JSON && isJSONDocument([]byte(value)):
+		doc = []byte(value)
+	case cfg.Base64 && len(value) >= embeddedBase64MinLength && _base64Regex.MatchString(value):
+		for _, enc := range _base64Encodings {
+			decoded, err := enc.DecodeString(value)
+			if err == nil && isJSONDocument(decoded) {
+				doc, encoding = decoded, enc
+				break
+			}
+		}
+	}
+	if doc == nil {
+		return nil, false, nil
+	}
+
+	masked, err := sm.m.maskDocument(doc, path, depth)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to mask embedded json: %+v", err)
+	}
+
+	encoded := string(masked)
+	if encoding != nil {
+		encoded = encoding.EncodeToString(masked)
+	}
+
+	ret, err = json.Marshal(encoded)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal embedded json: %+v", err)
+	}
+
+	return ret, true, nil
+}
+
+// isJSONDocument reports whether data is json object or array.
+func isJSONDocument(data []byte) bool {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" || trimmed[0] != '{' && trimmed[0] != '[' {
+		return false
+	}
+
+	return json.Valid(data)
+}
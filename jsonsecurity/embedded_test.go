@@ -0,0 +1,83 @@
+package jsonsecurity
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMasker_ShouldMaskEmbeddedJSON(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 5,
+		Triggers: map[string]TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: MaskerLabelPassword},
+		},
+		PathTriggers: map[string]TriggerOpts{
+			"webhook.payload.card": {ShouldAppear: false},
+		},
+		Embedded: EmbeddedConfig{JSON: true, Base64: true},
+	})
+	assert.NoError(t, err)
+
+	encode := func(enc *base64.Encoding, s string) string { return enc.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{
+			data: `{"payload": "{\"password\": \"qwerty\", \"id\": 1}"}`,
+			want: `{"payload": "{\"password\": \"******\", \"id\": 1}"}`,
+		},
+		{
+			data: `{"webhook": {"payload": "{\"card\": \"4111\", \"id\": 1}"}}`,
+			want: `{"webhook": {"payload": "{\"id\": 1}"}}`,
+		},
+		{
+			data: `{"list": ["[{\"password\": \"abc\"}]"]}`,
+			want: `{"list": ["[{\"password\": \"***\"}]"]}`,
+		},
+		{
+			data: `{"twice": "{\"inner\": \"{\\\"password\\\": \\\"abc\\\"}\"}"}`,
+			want: `{"twice": "{\"inner\": \"{\\\"password\\\": \\\"***\\\"}\"}"}`,
+		},
+		{
+			data: `{"body": "` + encode(base64.StdEncoding, `{"password":"qwerty"}`) + `"}`,
+			want: `{"body": "` + encode(base64.StdEncoding, `{"password":"******"}`) + `"}`,
+		},
+		{
+			data: `{"body": "` + encode(base64.RawURLEncoding, `{"password":"qwerty?"}`) + `"}`,
+			want: `{"body": "` + encode(base64.RawURLEncoding, `{"password":"*******"}`) + `"}`,
+		},
+		{data: `{"text": "{not json"}`, want: `{"text": "{not json"}`},
+		{data: `{"text": "abcdefghijklmnop"}`, want: `{"text": "abcdefghijklmnop"}`},
+		{data: `{"number": "42"}`, want: `{"number": "42"}`},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.Mask(tt.data)
+		assert.NoError(t, err, tt.data)
+		assert.Equal(t, tt.want, ret, tt.data)
+	}
+}
+
+func TestMasker_ShouldNotMaskEmbeddedJSON_WhenDisabled(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 5,
+		Triggers: map[string]TriggerOpts{"password": {ShouldAppear: true, MaskMethod: MaskerLabelPassword}},
+	})
+	assert.NoError(t, err)
+
+	data := `{"payload": "{\"password\": \"qwerty\"}"}`
+	ret, err := masker.Mask(data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, ret)
+}
+
+func TestMasker_ShouldFail_WhenEmbeddedJSONIsTooDeep(t *testing.T) {
+	masker, err := NewMasker(Config{MaxDepth: 2, Embedded: EmbeddedConfig{JSON: true}})
+	assert.NoError(t, err)
+
+	_, err = masker.Mask(`{"payload": "{\"a\": {\"b\": 1}}"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package jsonsecurity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaskerLabelEncrypt replaces the value with AES-GCM ciphertext tagged with the key ID, so authorized engineers are
+// able to restore it with Decrypt or DecryptLine. It's available only when Config.Encrypt is configured.
+//
+// Example: `enc:v1:support-2024:B64...`.
+const MaskerLabelEncrypt MaskerLabel = "ENCRYPT"
+
+// EncryptTokenPrefix is a prefix of ENCRYPT tokens. It contains the version of the token format.
+const EncryptTokenPrefix = "enc:v1:"
+
+// _encryptTokenRegex matches quoted ENCRYPT token in json, including the tokens in escaped quotes of json stored in
+// string values. Groups: 1 - backslashes escaping the opening quote, 2 - key ID, 3 - payload, 4 - backslashes escaping
+// the closing quote.
+var _encryptTokenRegex = regexp.MustCompile(
+	`(\\*)"` + regexp.QuoteMeta(EncryptTokenPrefix) + `([^:"\s\\]+):([A-Za-z0-9_-]+)(\\*)"`,
+)
+
+// EncryptConfig configures ENCRYPT label.
+type EncryptConfig struct {
+	// KeyID is written to the token, so it's known which key is needed for decryption. It must not contain ':'.
+	KeyID string
+	// Key of AES-128, AES-192 or AES-256 (16, 24 or 32 bytes). The label is not available if empty.
+	Key []byte
+}
+
+// encryptor produces ENCRYPT tokens.
+type encryptor struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// newEncryptor validates the config. It returns nil if no key is configured.
+func newEncryptor(cfg EncryptConfig) (*encryptor, error) {
+	if len(cfg.Key) == 0 {
+		return nil, nil
+	}
+
+	if err := validateKey(cfg.KeyID, cfg.Key); err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %+v", err)
+	}
+
+	aead, err := newAEAD(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key '%s': %+v", cfg.KeyID, err)
+	}
+
+	return &encryptor{keyID: cfg.KeyID, aead: aead}, nil
+}
+
+// newAEAD returns AES-GCM cipher.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// mask is StrategyMaskerFunc of ENCRYPT label. The value is marshalled to json before encryption, so its type is
+// restored by Decrypt. Strategy is ignored.
+func (e *encryptor) mask(key string, value interface{}, _ *MaskStrategy) (MaskResult, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return MaskResult{}, fmt.Errorf("failed to marshal value: %+v", err)
+	}
+
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return MaskResult{}, fmt.Errorf("failed to generate nonce: %+v", err)
+	}
+
+	sealed := e.aead.Seal(nonce, nonce, plaintext, []byte(EncryptTokenPrefix+e.keyID))
+
+	return MaskResult{
+		Key:   key,
+		Value: EncryptTokenPrefix + e.keyID + ":" + base64.RawURLEncoding.EncodeToString(sealed),
+	}, nil
+}
+
+// Decrypt restores the value from ENCRYPT token using keys mapped by their IDs. Numbers are returned as json.Number.
+func Decrypt(token string, keys map[string][]byte) (any, error) {
+	plaintext, err := decryptToken(token, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValue(plaintext)
+}
+
+// DecryptLine restores the values of all ENCRYPT tokens found in json log line using keys mapped by their IDs. The
+// tokens are replaced with the original json values. The tokens found in json stored in string values (e.g. fields of
+// TypeJSONEscapeSecure) are replaced with the values escaped the same way the token was.
+func DecryptLine(line string, keys map[string][]byte) (string, error) {
+	var (
+		b    strings.Builder
+		errs []string
+		last int
+	)
+
+	for _, idx := range _encryptTokenRegex.FindAllStringSubmatchIndex(line, -1) {
+		opening, closing := line[idx[2]:idx[3]], line[idx[8]:idx[9]]
+		depth, ok := escapeDepth(len(opening))
+		if !ok || opening != closing {
+			continue
+		}
+
+		plaintext, err := decryptToken(EncryptTokenPrefix+line[idx[4]:idx[5]]+":"+line[idx[6]:idx[7]], keys)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		value := string(plaintext)
+		for i := 0; i < depth; i++ {
+			value = escapeJSONString(value)
+		}
+
+		b.WriteString(line[last:idx[0]])
+		b.WriteString(value)
+		last = idx[1]
+	}
+	if len(errs) != 0 {
+		return "", fmt.Errorf("failed to decrypt tokens: %s", strings.Join(errs, "; "))
+	}
+	b.WriteString(line[last:])
+
+	return b.String(), nil
+}
+
+// escapeDepth returns how many times the quote preceded by n backslashes was escaped. Every level of escaping doubles
+// the backslashes and adds one more escaping the quote itself: `"`, `\"`, `\\\"`, etc. ok is false if n is not
+// produced by escaping.
+func escapeDepth(n int) (depth int, ok bool) {
+	for escaped := 0; escaped < n; depth++ {
+		escaped = 2*escaped + 1
+		if escaped > n {
+			return 0, false
+		}
+	}
+
+	return depth, true
+}
+
+// escapeJSONString escapes the text to be placed inside json string without surrounding quotes.
+func escapeJSONString(text string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(text)
+
+	quoted := strings.TrimSuffix(b.String(), "\n")
+	return quoted[1 : len(quoted)-1]
+}
+
+// decryptToken returns json of the value encrypted in token.
+func decryptToken(token string, keys map[string][]byte) ([]byte, error) {
+	rest, ok := strings.CutPrefix(token, EncryptTokenPrefix)
+	if !ok {
+		return nil, fmt.Errorf("invalid token: missing prefix '%s'", EncryptTokenPrefix)
+	}
+
+	keyID, payload, ok := strings.Cut(rest, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid token: missing key id")
+	}
+
+	key, ok := keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key '%s'", keyID)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key '%s': %+v", keyID, err)
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token payload: %+v", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("invalid token payload: too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(EncryptTokenPrefix+keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token with key '%s': %+v", keyID, err)
+	}
+	if !json.Valid(plaintext) {
+		return nil, fmt.Errorf("decrypted value is not valid json")
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,117 @@
+package jsonsecurity
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMasker_ShouldEncryptValues(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	masker, err := NewMasker(Config{
+		MaxDepth: 1,
+		Triggers: map[string]TriggerOpts{
+			"email": {ShouldAppear: true, MaskMethod: MaskerLabelEncrypt},
+			"limit": {ShouldAppear: true, MaskMethod: MaskerLabelEncrypt},
+		},
+		Encrypt: EncryptConfig{KeyID: "support", Key: key},
+	})
+	assert.NoError(t, err)
+
+	line := `{"email":"john@example.com","limit":12345678901234567890,"name":"john"}`
+	masked, err := masker.Mask(line)
+	assert.NoError(t, err)
+	assert.NotContains(t, masked, "john@example.com")
+	assert.NotContains(t, masked, "12345678901234567890")
+	assert.Equal(t, 2, strings.Count(masked, `"`+EncryptTokenPrefix+`support:`))
+
+	restored, err := DecryptLine(masked, map[string][]byte{"support": key})
+	assert.NoError(t, err)
+	assert.Equal(t, line, restored)
+
+	var record map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(masked), &record))
+
+	value, err := Decrypt(record["email"], map[string][]byte{"support": key})
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", value)
+
+	value, err = Decrypt(record["limit"], map[string][]byte{"support": key})
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("12345678901234567890"), value)
+
+	again, err := masker.Mask(line)
+	assert.NoError(t, err)
+	assert.NotEqual(t, masked, again)
+}
+
+func TestDecryptLine_ShouldRestoreEscapedTokens(t *testing.T) {
+	keys := map[string][]byte{"k1": []byte("0123456789abcdef")}
+
+	masker, err := NewMasker(Config{Encrypt: EncryptConfig{KeyID: "k1", Key: keys["k1"]}})
+	assert.NoError(t, err)
+
+	ret, err := masker.MaskWith(MaskerLabelEncrypt, "password", `a"b\c`)
+	assert.NoError(t, err)
+	token := ret.Value.(string)
+
+	// The token is stored in json which is stored in string of json which is stored in string of the line.
+	inner := escapeJSONString(`{"password":"` + token + `"}`)
+	line := `{"outer":"` + escapeJSONString(`{"inner":"`+inner+`"}`) + `","plain":"` + token + `"}`
+
+	restored, err := DecryptLine(line, keys)
+	assert.NoError(t, err)
+
+	var outer, nested, secret map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(restored), &outer))
+	assert.Equal(t, `a"b\c`, outer["plain"])
+	assert.NoError(t, json.Unmarshal([]byte(outer["outer"].(string)), &nested))
+	assert.NoError(t, json.Unmarshal([]byte(nested["inner"].(string)), &secret))
+	assert.Equal(t, `a"b\c`, secret["password"])
+
+	// Quotes escaped differently don't enclose the token.
+	mismatched := `{"a":"\"` + token + `"}`
+	restored, err = DecryptLine(mismatched, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, mismatched, restored)
+}
+
+func TestDecrypt_ShouldFail_WhenTokenOrKeyIsInvalid(t *testing.T) {
+	key := []byte("0123456789abcdef")
+
+	masker, err := NewMasker(Config{Encrypt: EncryptConfig{KeyID: "k1", Key: key}})
+	assert.NoError(t, err)
+
+	ret, err := masker.MaskWith(MaskerLabelEncrypt, "secret", "value")
+	assert.NoError(t, err)
+	token := ret.Value.(string)
+
+	_, err = Decrypt(token, map[string][]byte{"k2": key})
+	assert.Error(t, err)
+	_, err = Decrypt(token, map[string][]byte{"k1": []byte("fedcba9876543210")})
+	assert.Error(t, err)
+	_, err = Decrypt(strings.Replace(token, "k1", "k2", 1), map[string][]byte{"k2": key})
+	assert.Error(t, err)
+	_, err = Decrypt(token[:len(token)-2], map[string][]byte{"k1": key})
+	assert.Error(t, err)
+	_, err = Decrypt("value", map[string][]byte{"k1": key})
+	assert.Error(t, err)
+
+	_, err = DecryptLine(`{"secret":"`+token+`"}`, map[string][]byte{})
+	assert.Error(t, err)
+}
+
+func TestNewMasker_ShouldFail_WhenEncryptConfigIsInvalid(t *testing.T) {
+	configs := []EncryptConfig{
+		{Key: []byte("0123456789abcdef")},
+		{KeyID: "a:b", Key: []byte("0123456789abcdef")},
+		{KeyID: "k", Key: []byte("short")},
+	}
+
+	for _, cfg := range configs {
+		_, err := NewMasker(Config{Encrypt: cfg})
+		assert.Error(t, err)
+	}
+}
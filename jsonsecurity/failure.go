@@ -0,0 +1,155 @@
+package jsonsecurity
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces the values which cannot be masked safely.
+const RedactedValue = "[REDACTED]"
+
+// FailurePolicy defines what happens to the data that failed to be masked (e.g. invalid json or max depth exceeded).
+type FailurePolicy uint8
+
+const (
+	// FailurePolicyRedact replaces the whole value with RedactedValue. It's the default policy.
+	FailurePolicyRedact FailurePolicy = iota
+	// FailurePolicyDrop removes the value from the record.
+	FailurePolicyDrop
+	// FailurePolicyBestEffort masks the values of trigger keys found in the raw text using regular expressions. It
+	// keeps the rest of the text readable, but may miss secrets stored in unusual formatting.
+	FailurePolicyBestEffort
+)
+
+// Fallback returns the replacement for the data which failed to be masked according to Config.FailurePolicy. ok is
+// false when the data must be dropped. Nil masker uses the default policy.
+func (m *Masker) Fallback(data string) (string, bool) {
+	if m == nil {
+		return RedactedValue, true
+	}
+
+	switch m.cfg.FailurePolicy {
+	case FailurePolicyDrop:
+		return "", false
+	case FailurePolicyBestEffort:
+		return m.maskRaw(data), true
+	default:
+		return RedactedValue, true
+	}
+}
+
+// _rawPairRegex matches json-like (`"key": "value"`) and assignment-like (`key=value`, `key: value`) pairs in raw text.
+// String values are allowed to be unterminated. Groups: 1 - opening quote, 2 - key, 3 - closing quote, 4 - separator,
+// 5 - value.
+var _rawPairRegex = regexp.MustCompile(`(["']?)\b(\w[\w-]*)(\\?["']?)(\s*[=:]\s*)("(?:[^"\\]|\\.)*"?|[^\s,;&}\]]*)`)
+
+// _rawElementRegex matches start tag of xml element along with the text following it. Groups: 1 - local name of the
+// element, 2 - text.
+var _rawElementRegex = regexp.MustCompile(`<(?:[\w.-]+:)?([\w.-]+)(?:\s[^<>]*)?>([^<]*)`)
+
+// maskRaw masks the values of trigger keys in the raw text. Keys are resolved with rawTrigger, so exact, pattern and
+// path triggers are applied. Both the text of xml elements and key-value pairs (including xml attributes) are masked.
+func (m *Masker) maskRaw(data string) string {
+	return m.maskRawPairs(m.maskRawElements(data))
+}
+
+// maskRawElements masks the text of triggered xml elements. Only the text preceding nested elements is masked, the
+// nested ones are matched on their own.
+func (m *Masker) maskRawElements(data string) string {
+	var (
+		b    strings.Builder
+		last int
+	)
+
+	for _, idx := range _rawElementRegex.FindAllStringSubmatchIndex(data, -1) {
+		key, text := data[idx[2]:idx[3]], data[idx[4]:idx[5]]
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		trigger, ok := m.rawTrigger(key)
+		if !ok {
+			continue
+		}
+
+		masked := RedactedValue
+		if ret, ok, err := m.MaskTrigger(trigger, key, text); ok && err == nil {
+			if s, isString := ret.Value.(string); isString {
+				masked = s
+			}
+		}
+
+		b.WriteString(data[last:idx[4]])
+		b.WriteString(masked)
+		last = idx[5]
+	}
+	b.WriteString(data[last:])
+
+	return b.String()
+}
+
+// maskRawPairs masks the values of triggered key-value pairs matched by _rawPairRegex.
+func (m *Masker) maskRawPairs(data string) string {
+	var b strings.Builder
+
+	last, pos := 0, 0
+	for pos < len(data) {
+		idx := _rawPairRegex.FindStringSubmatchIndex(data[pos:])
+		if idx == nil {
+			break
+		}
+		for i := range idx {
+			idx[i] += pos
+		}
+
+		key, value := data[idx[4]:idx[5]], data[idx[10]:idx[11]]
+
+		trigger, ok := m.rawTrigger(key)
+		if !ok {
+			// The value may contain pairs itself, e.g. `"user": {"password": ...`, so the search goes on after the key.
+			pos = idx[5]
+			continue
+		}
+
+		quote := ""
+		if strings.HasPrefix(value, `"`) {
+			quote = `"`
+			value = strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`)
+		}
+
+		masked := RedactedValue
+		if ret, ok, err := m.MaskTrigger(trigger, key, value); ok && err == nil {
+			if s, isString := ret.Value.(string); isString {
+				masked = s
+			}
+		}
+
+		b.WriteString(data[last:idx[10]])
+		b.WriteString(quote + masked + quote)
+		last, pos = idx[11], idx[11]
+	}
+	b.WriteString(data[last:])
+
+	return b.String()
+}
+
+// rawTrigger returns TriggerOpts of the key found in raw text. Exact and pattern triggers are looked up the same way
+// Trigger does. The structure of raw text is unknown, so path triggers are matched by their last key.
+func (m *Masker) rawTrigger(key string) (TriggerOpts, bool) {
+	if trigger, ok := m.Trigger(key); ok {
+		return trigger, true
+	}
+
+	for _, trigger := range m.pathTriggers {
+		segment := trigger.segments[len(trigger.segments)-1]
+		if segment.kind != pathSegmentKey {
+			continue
+		}
+		caseSensitive := trigger.opts.CaseSensitive
+		if caseSensitive && segment.key == key || !caseSensitive && strings.EqualFold(segment.key, key) {
+			return trigger.opts, true
+		}
+	}
+
+	return TriggerOpts{}, false
+}
@@ -0,0 +1,99 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// withFailurePolicy configures shallow masker removing passwords and failing with the policy.
+func withFailurePolicy(policy FailurePolicy) func(cfg *Config) {
+	return func(cfg *Config) {
+		cfg.MaxDepth = 2
+		cfg.FailurePolicy = policy
+		cfg.Triggers["password"] = TriggerOpts{ShouldAppear: false, MaskMethod: MaskerLabelPassword}
+	}
+}
+
+func TestMasker_Fallback_ShouldRedact_ByDefault(t *testing.T) {
+	masker := newTestMasker(withFailurePolicy(FailurePolicy(0)))
+
+	ret, ok := masker.Fallback(`{"password": "qwerty123"`)
+	assert.True(t, ok)
+	assert.Equal(t, RedactedValue, ret)
+}
+
+func TestMasker_Fallback_ShouldDrop(t *testing.T) {
+	masker := newTestMasker(withFailurePolicy(FailurePolicyDrop))
+
+	_, ok := masker.Fallback(`{"password": "qwerty123"`)
+	assert.False(t, ok)
+}
+
+func TestMasker_Fallback_ShouldMaskKnownKeys_WhenBestEffort(t *testing.T) {
+	masker := newTestMasker(withFailurePolicy(FailurePolicyBestEffort))
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{
+			data: `{"Password": "qwerty123", "card": 4111111111111111, "comment": "password"`,
+			want: `{"Password": "[REDACTED]", "card": 411111******1111, "comment": "password"`,
+		},
+		{
+			data: `{"user": {"password": "unterminated`,
+			want: `{"user": {"password": "[REDACTED]"`,
+		},
+		{
+			data: `password=qwerty123&card=4111111111111111&my_password=keep`,
+			want: `password=[REDACTED]&card=411111******1111&my_password=keep`,
+		},
+		{
+			data: `{\"password\":\"qwerty123\"}`,
+			want: `{\"password\":[REDACTED]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		ret, ok := masker.Fallback(tt.data)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, ret)
+	}
+}
+
+func TestMasker_Fallback_ShouldMaskPatternAndPathKeys_WhenBestEffort(t *testing.T) {
+	masker := newTestMasker(withFailurePolicy(FailurePolicyBestEffort), func(cfg *Config) {
+		cfg.PatternTriggers = map[string]TriggerOpts{`(?i)_token$`: {ShouldAppear: true, MaskMethod: MaskerLabelPassword}}
+		cfg.PathTriggers = map[string]TriggerOpts{"user.pin": {ShouldAppear: true, MaskMethod: MaskerLabelPassword}}
+	})
+
+	ret, ok := masker.Fallback(`{"access_token":"tok123","user":{"id":1,"pin":1234`)
+	assert.True(t, ok)
+	assert.Equal(t, `{"access_token":"******","user":{"id":1,"pin":****`, ret)
+}
+
+func TestMasker_Fallback_ShouldMaskXML_WhenBestEffort(t *testing.T) {
+	masker := newTestMasker(withFailurePolicy(FailurePolicyBestEffort))
+
+	ret, ok := masker.Fallback(`<login user="a"><ns:card kind="visa">4111111111111111</ns:card><password>hunter2</password>`)
+	assert.True(t, ok)
+	assert.Equal(
+		t,
+		`<login user="a"><ns:card kind="visa">411111******1111</ns:card><password>[REDACTED]</password>`,
+		ret,
+	)
+
+	ret, ok = masker.Fallback(`<login user="a"password="hunter2"/>`)
+	assert.True(t, ok)
+	assert.Equal(t, `<login user="a"password="[REDACTED]"/>`, ret)
+}
+
+func TestMasker_Mask_ShouldFail_WhenInputIsMalformed(t *testing.T) {
+	masker := newTestMasker(withFailurePolicy(FailurePolicyRedact))
+
+	for _, data := range []string{``, `{"password": "qwerty123"`, `{"a": {"b": {"c": {"password": "qwerty123"}}}}`} {
+		_, err := masker.Mask(data)
+		assert.Error(t, err)
+		assert.NotContains(t, err.Error(), "qwerty123")
+	}
+}
@@ -0,0 +1,150 @@
+package jsonsecurity
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaskForm masks application/x-www-form-urlencoded body (or URL query string without leading '?') using the triggers.
+// Keys written in bracket notation are split into path, e.g. `user[cards][0][number]` is matched by `number`, `cards`
+// and `user` triggers and by `user.cards[0].number` path trigger, empty brackets (`tags[]`) are ignored. The pairs are
+// kept in order, untouched pairs stay exactly as they were, triggered pairs are removed if the trigger doesn't allow
+// them to appear.
+func (m *Masker) MaskForm(data string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(data))
+
+	written := false
+	for _, pair := range strings.Split(data, "&") {
+		masked, ok, err := m.maskFormPair(pair)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+
+		if written {
+			b.WriteByte('&')
+		}
+		b.WriteString(masked)
+		written = true
+	}
+
+	return b.String(), nil
+}
+
+// MaskQuery masks query string of the URL using the triggers the same way MaskForm does. The URL without '?' is
+// treated as query string itself, so form bodies are masked as well. The rest of the URL, including fragment, is kept
+// as is.
+func (m *Masker) MaskQuery(rawURL string) (string, error) {
+	prefix, query := "", rawURL
+	if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+		prefix, query = rawURL[:i+1], rawURL[i+1:]
+	}
+
+	fragment := ""
+	if i := strings.IndexByte(query, '#'); i >= 0 {
+		query, fragment = query[:i], query[i:]
+	}
+
+	masked, err := m.MaskForm(query)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + masked + fragment, nil
+}
+
+// maskFormPair masks a single `key=value` pair. ok is false when the pair must be removed.
+func (m *Masker) maskFormPair(pair string) (masked string, ok bool, err error) {
+	rawKey, rawValue, hasValue := strings.Cut(pair, "=")
+	if !hasValue {
+		return pair, true, nil
+	}
+
+	key, err := url.QueryUnescape(rawKey)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid form key '%s': %+v", rawKey, err)
+	}
+
+	path := formPath(key)
+	opts, triggered := m.findFormTrigger(path)
+	if !triggered && m.allowlist == nil {
+		return pair, true, nil
+	}
+
+	value, err := url.QueryUnescape(rawValue)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid value of form key '%s': %+v", key, err)
+	}
+
+	if !triggered {
+		denied, deny, err := m.maskDenied(path, value)
+		switch {
+		case err != nil:
+			return "", false, err
+		case !deny:
+			return pair, true, nil
+		}
+		return rawKey + "=" + escapeFormValue(fmt.Sprint(denied)), true, nil
+	}
+
+	ret, ok, err := m.MaskTrigger(opts, key, value)
+	if err != nil {
+		return "", false, fmt.Errorf("fail while masking form key '%s': %+v", key, err)
+	}
+	if !ok {
+		return "", false, nil
+	}
+
+	return rawKey + "=" + escapeFormValue(fmt.Sprint(ret.Value)), true, nil
+}
+
+// escapeFormValue escapes masked value keeping mask symbols '*' readable, they are allowed in query strings.
+func escapeFormValue(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "%2A", "*")
+}
+
+// findFormTrigger returns the trigger of the value located at path. The value is triggered by any of its ancestors
+// too, the same way nested json value is masked along with the triggered object.
+func (m *Masker) findFormTrigger(path []pathElem) (TriggerOpts, bool) {
+	for i := 1; i <= len(path); i++ {
+		if opts, ok := m.findTrigger(path[:i]); ok {
+			return opts, true
+		}
+	}
+
+	return TriggerOpts{}, false
+}
+
+// formPath splits the key written in bracket notation into path. Malformed keys are used as is.
+func formPath(key string) []pathElem {
+	name, rest, found := strings.Cut(key, "[")
+	if !found || name == "" {
+		return []pathElem{{key: key}}
+	}
+
+	path := []pathElem{{key: name}}
+	rest = "[" + rest
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return []pathElem{{key: key}}
+		}
+
+		segment := rest[1:end]
+		rest = rest[end+1:]
+		switch index, err := strconv.Atoi(segment); {
+		case segment == "":
+		case err == nil && index >= 0:
+			path = append(path, pathElem{index: index, isIndex: true})
+		default:
+			path = append(path, pathElem{key: segment})
+		}
+	}
+
+	return path
+}
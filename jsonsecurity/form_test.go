@@ -0,0 +1,69 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMasker_MaskForm(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: ``, want: ``},
+		{data: `user=john&password=qwerty`, want: `user=john&password=******`},
+		{data: `user=j%20o+hn&password=a%26b+c`, want: `user=j%20o+hn&password=*****`},
+		{data: `secret=1&user=john&secret=2`, want: `user=john`},
+		{data: `token=abc&flag&password`, want: `token=%5BHIDDEN%5D&flag&password`},
+		{data: `user[password]=abc&user[name]=john`, want: `user[password]=***&user[name]=john`},
+		{data: `user%5Bcard%5D=4111111111111111`, want: `user%5Bcard%5D=411111******1111`},
+		{
+			data: `password[0]=abc&password[]=ab&cards[0][card]=4111111111111111`,
+			want: `password[0]=***&password[]=**&cards[0][card]=411111******1111`,
+		},
+		{data: `Envelope[Body][Pay][cvv]=123&cvv=123`, want: `Envelope[Body][Pay][cvv]=***&cvv=123`},
+		{data: `[password]=abc&a]b=1`, want: `[password]=abc&a]b=1`},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.MaskForm(tt.data)
+		assert.NoError(t, err, tt.data)
+		assert.Equal(t, tt.want, ret, tt.data)
+	}
+}
+
+func TestMasker_MaskForm_ShouldFail_WhenEncodingIsInvalid(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	_, err := masker.MaskForm(`password=%zz`)
+	assert.Error(t, err)
+
+	_, err = masker.MaskForm(`%zz=1`)
+	assert.Error(t, err)
+}
+
+func TestMasker_MaskQuery(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: `https://example.com/login`, want: `https://example.com/login`},
+		{data: `https://example.com/login?`, want: `https://example.com/login?`},
+		{
+			data: `https://example.com/login?user=john&password=qwerty#password=x`,
+			want: `https://example.com/login?user=john&password=******#password=x`,
+		},
+		{data: `/cb?secret=1&code=2`, want: `/cb?code=2`},
+		{data: `user=john&password=qwerty`, want: `user=john&password=******`},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.MaskQuery(tt.data)
+		assert.NoError(t, err, tt.data)
+		assert.Equal(t, tt.want, ret, tt.data)
+	}
+}
@@ -0,0 +1,132 @@
+package jsonsecurity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaskerLabelHash replaces the value with keyed HMAC-SHA256 token, so the same value can be correlated across log
+// records without being disclosed. It's available only when Config.Hash is configured.
+//
+// Example: `hash:2024q1:5f1e0c9a7b3d2e4f`.
+const MaskerLabelHash MaskerLabel = "HASH"
+
+const (
+	defaultHashPrefix = "hash:"
+	defaultHashLength = 16
+)
+
+// HashConfig configures HASH label.
+type HashConfig struct {
+	// Keys used for hashing. The key with the latest ValidFrom which is not in the future is used, so tokens stay stable
+	// within the rotation period. The label is not available if empty.
+	Keys []HashKey
+	// Prefix of the token. "hash:" is used if empty.
+	Prefix string
+	// Length is a number of hex characters of the digest kept in the token, 16 if zero. The full digest is 64
+	// characters long.
+	Length int
+}
+
+// HashKey is a secret used by HASH label.
+type HashKey struct {
+	// ID is written to the token, so it's known which key the token was produced with. It must not contain ':'.
+	ID string
+	// Secret of HMAC.
+	Secret []byte
+	// ValidFrom is the moment the key becomes active. Zero value means the key is active from the beginning.
+	ValidFrom time.Time
+}
+
+// hasher produces HASH tokens.
+type hasher struct {
+	// keys are sorted by ValidFrom descending.
+	keys   []HashKey
+	prefix string
+	length int
+	now    func() time.Time
+}
+
+// newHasher validates the config. It returns nil if no keys are configured.
+func newHasher(cfg HashConfig) (*hasher, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, nil
+	}
+
+	h := &hasher{
+		keys:   make([]HashKey, 0, len(cfg.Keys)),
+		prefix: cfg.Prefix,
+		length: cfg.Length,
+		now:    time.Now,
+	}
+	if h.prefix == "" {
+		h.prefix = defaultHashPrefix
+	}
+	if h.length == 0 {
+		h.length = defaultHashLength
+	}
+	if h.length < 0 || h.length > hex.EncodedLen(sha256.Size) {
+		return nil, fmt.Errorf("invalid hash length: %d", cfg.Length)
+	}
+
+	ids := make(map[string]bool, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		if err := validateKey(key.ID, key.Secret); err != nil {
+			return nil, fmt.Errorf("invalid hash key: %+v", err)
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("duplicate hash key '%s'", key.ID)
+		}
+		ids[key.ID] = true
+		h.keys = append(h.keys, key)
+	}
+
+	sort.SliceStable(h.keys, func(i, j int) bool { return h.keys[i].ValidFrom.After(h.keys[j].ValidFrom) })
+
+	return h, nil
+}
+
+// validateKey checks ID and secret of the key.
+func validateKey(id string, secret []byte) error {
+	switch {
+	case id == "":
+		return fmt.Errorf("empty key id")
+	case strings.Contains(id, ":"):
+		return fmt.Errorf("key id '%s' contains ':'", id)
+	case len(secret) == 0:
+		return fmt.Errorf("empty secret of key '%s'", id)
+	}
+
+	return nil
+}
+
+// activeKey returns the key valid at the moment.
+func (h *hasher) activeKey() (HashKey, error) {
+	now := h.now()
+	for _, key := range h.keys {
+		if !key.ValidFrom.After(now) {
+			return key, nil
+		}
+	}
+
+	return HashKey{}, fmt.Errorf("no active hash key")
+}
+
+// mask is StrategyMaskerFunc of HASH label. Strategy is ignored.
+func (h *hasher) mask(key string, value interface{}, _ *MaskStrategy) (MaskResult, error) {
+	hk, err := h.activeKey()
+	if err != nil {
+		return MaskResult{}, err
+	}
+
+	mac := hmac.New(sha256.New, hk.Secret)
+	mac.Write([]byte(fmt.Sprint(value)))
+	digest := hex.EncodeToString(mac.Sum(nil))
+
+	return MaskResult{Key: key, Value: h.prefix + hk.ID + ":" + digest[:h.length]}, nil
+}
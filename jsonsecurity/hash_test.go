@@ -0,0 +1,103 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMasker_ShouldHashValues(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 1,
+		Triggers: map[string]TriggerOpts{"email": {ShouldAppear: true, MaskMethod: MaskerLabelHash}},
+		Hash:     HashConfig{Keys: []HashKey{{ID: "q1", Secret: []byte("first")}}},
+	})
+	assert.NoError(t, err)
+
+	first, err := masker.Mask(`{"email":"john@example.com"}`)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^\{"email":"hash:q1:[0-9a-f]{16}"\}$`), first)
+
+	again, err := masker.Mask(`{"email":"john@example.com"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	other, err := masker.Mask(`{"email":"jane@example.com"}`)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, other)
+}
+
+func TestHasher_ShouldRotateKeys(t *testing.T) {
+	rotation := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	h, err := newHasher(HashConfig{
+		Keys: []HashKey{
+			{ID: "q1", Secret: []byte("first")},
+			{ID: "q2", Secret: []byte("second"), ValidFrom: rotation},
+		},
+	})
+	assert.NoError(t, err)
+
+	h.now = func() time.Time { return rotation.Add(-time.Hour) }
+	ret, err := h.mask("email", "john@example.com", nil)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^hash:q1:[0-9a-f]{16}$`), ret.Value)
+
+	h.now = func() time.Time { return rotation }
+	ret, err = h.mask("email", "john@example.com", nil)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^hash:q2:[0-9a-f]{16}$`), ret.Value)
+
+	h, err = newHasher(HashConfig{Keys: []HashKey{{ID: "future", Secret: []byte("secret"), ValidFrom: rotation}}})
+	assert.NoError(t, err)
+
+	h.now = func() time.Time { return rotation.Add(-time.Hour) }
+	_, err = h.mask("email", "john@example.com", nil)
+	assert.Error(t, err)
+}
+
+func TestMasker_ShouldHashValues_WithCustomFormat(t *testing.T) {
+	masker, err := NewMasker(Config{Hash: HashConfig{
+		Keys:   []HashKey{{ID: "k", Secret: []byte("secret")}},
+		Prefix: "tok_",
+		Length: 64,
+	}})
+	assert.NoError(t, err)
+
+	ret, err := masker.MaskWith(MaskerLabelHash, "id", 42)
+	assert.NoError(t, err)
+	// HMAC-SHA256("secret", "42")
+	assert.Equal(t, "tok_k:93c121e7aa437a1e01e3c512c6f0ce3c821a839025dca4408f85616de4aaee70", ret.Value)
+}
+
+func TestNewMasker_ShouldFail_WhenHashConfigIsInvalid(t *testing.T) {
+	configs := []HashConfig{
+		{Keys: []HashKey{{ID: "", Secret: []byte("s")}}},
+		{Keys: []HashKey{{ID: "a:b", Secret: []byte("s")}}},
+		{Keys: []HashKey{{ID: "k"}}},
+		{Keys: []HashKey{{ID: "k", Secret: []byte("s")}, {ID: "k", Secret: []byte("t")}}},
+		{Keys: []HashKey{{ID: "k", Secret: []byte("s")}}, Length: 65},
+	}
+
+	for _, cfg := range configs {
+		_, err := NewMasker(Config{Hash: cfg})
+		assert.Error(t, err)
+	}
+
+	_, err := NewMasker(Config{
+		Hash:   HashConfig{Keys: []HashKey{{ID: "k", Secret: []byte("s")}}},
+		Labels: map[MaskerLabel]MaskerFunc{MaskerLabelHash: upperMasker},
+	})
+	assert.Error(t, err)
+
+	_, err = NewMasker(Config{Triggers: map[string]TriggerOpts{"id": {ShouldAppear: true, MaskMethod: MaskerLabelHash}}})
+	assert.Error(t, err)
+
+	masker, err := NewMasker(Config{Hash: HashConfig{
+		Keys: []HashKey{{ID: "k", Secret: []byte("s"), ValidFrom: time.Now().Add(time.Hour)}},
+	}})
+	assert.NoError(t, err)
+	_, err = masker.MaskWith(MaskerLabelHash, "id", 42)
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package jsonsecurity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// _headerNameRegex matches valid header name (token of RFC 9110).
+var _headerNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// MaskHeaders masks raw block of HTTP headers (`Name: value` lines) using header names as trigger keys. Request or
+// status line preceding the headers is kept as is, so is everything after the empty line ending the block (e.g. the
+// body of dumped request). Folded values of triggered headers are unfolded, triggered headers are removed if the
+// trigger doesn't allow them to appear. Line endings are preserved.
+func (m *Masker) MaskHeaders(block string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(block))
+
+	lines := strings.SplitAfter(block, "\n")
+	for i := 0; i < len(lines); {
+		content := strings.TrimRight(lines[i], "\r\n")
+		if content == "" {
+			b.WriteString(strings.Join(lines[i:], ""))
+			break
+		}
+
+		name, value, ok := strings.Cut(content, ":")
+		if !ok || !_headerNameRegex.MatchString(name) {
+			if i != 0 {
+				// The line is never reported, since it may contain the value of sensitive header.
+				return "", fmt.Errorf("invalid header line %d", i+1)
+			}
+			b.WriteString(lines[i])
+			i++
+			continue
+		}
+
+		// Continuation lines of obsolete line folding belong to the same header.
+		end := i + 1
+		for end < len(lines) && (strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t")) {
+			value += " " + strings.TrimSpace(lines[end])
+			end++
+		}
+
+		masked, changed, ok, err := m.maskHeader(name, strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("fail while masking header '%s': %+v", name, err)
+		}
+		switch {
+		case !changed:
+			b.WriteString(strings.Join(lines[i:end], ""))
+		case ok:
+			last := lines[end-1]
+			space := value[:len(value)-len(strings.TrimLeft(value, " \t"))]
+			b.WriteString(name + ":" + space + masked + last[len(strings.TrimRight(last, "\r\n")):])
+		}
+		i = end
+	}
+
+	return b.String(), nil
+}
+
+// maskHeader masks the value of the header with the trigger. The value which is not triggered is masked in
+// default-deny mode unless it's allowlisted. changed is false when the value stays as is, ok is false when the header
+// must be removed.
+func (m *Masker) maskHeader(name, value string) (masked string, changed, ok bool, err error) {
+	opts, triggered := m.Trigger(name)
+	if !triggered {
+		denied, deny, err := m.maskDenied([]pathElem{{key: name}}, value)
+		if err != nil || !deny {
+			return "", false, true, err
+		}
+		return fmt.Sprint(denied), true, true, nil
+	}
+
+	ret, ok, err := m.MaskTrigger(opts, name, value)
+	if err != nil || !ok {
+		return "", true, false, err
+	}
+
+	return fmt.Sprint(ret.Value), true, true, nil
+}
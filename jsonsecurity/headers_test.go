@@ -0,0 +1,43 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMasker_MaskHeaders(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: ``, want: ``},
+		{
+			data: "Host: example.com\r\nAuthorization: Bearer abc\r\n",
+			want: "Host: example.com\r\nAuthorization: **********\r\n",
+		},
+		{
+			data: "POST http://example.com/login HTTP/1.1\nauthorization:abc\nSecret: 1\nAccept: */*\n\npassword: body",
+			want: "POST http://example.com/login HTTP/1.1\nauthorization:***\nAccept: */*\n\npassword: body",
+		},
+		{
+			data: "HTTP/1.1 200 OK\r\nToken: a\r\n b\r\nX-Folded: a\r\n\tb\r\n",
+			want: "HTTP/1.1 200 OK\r\nToken: [HIDDEN]\r\nX-Folded: a\r\n\tb\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.MaskHeaders(tt.data)
+		assert.NoError(t, err, tt.data)
+		assert.Equal(t, tt.want, ret, tt.data)
+	}
+}
+
+func TestMasker_MaskHeaders_ShouldFail_WhenLineIsInvalid(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	_, err := masker.MaskHeaders("Host: example.com\r\nAuthorization Bearer supersecret\r\n")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "supersecret")
+}
@@ -0,0 +1,24 @@
+package jsonsecurity
+
+// newTestMasker returns masker with password, card and secret triggers shared by the tests. configure adjusts the config
+// for the specific test, e.g. adds triggers or changes the failure policy.
+func newTestMasker(configure ...func(cfg *Config)) *Masker {
+	cfg := Config{
+		MaxDepth: 5,
+		Triggers: map[string]TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: MaskerLabelPassword},
+			"card":     {ShouldAppear: true, MaskMethod: MaskerLabelCardNumber},
+			"secret":   {ShouldAppear: false},
+		},
+	}
+	for _, c := range configure {
+		c(&cfg)
+	}
+
+	masker, err := NewMasker(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	return masker
+}
@@ -0,0 +1,137 @@
+package jsonsecurity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaskerLabelJWT masks JSON Web Token keeping it useful for debugging: the header and non-sensitive claims stay
+// readable, the other claims are masked and the signature is stripped, so the token cannot be replayed. The result
+// contains json of the header and the claims separated by '.'. Authorization scheme (e.g. `Bearer `) is kept, values
+// which are not JWT are masked completely. See JWTConfig. Tokens found by DetectorJWT are masked with PASSWORD label,
+// so this one is used only when it's set explicitly, e.g. in triggers.
+//
+// Example: `Bearer {"alg":"HS256","typ":"JWT"}.{"email":"*************","exp":1700000000,"sub":"42"}`.
+const MaskerLabelJWT MaskerLabel = "JWT"
+
+// _defaultJWTKeepClaims are kept readable unless JWTConfig.KeepClaims is specified.
+var _defaultJWTKeepClaims = []string{"iss", "sub", "exp", "aud"}
+
+// JWTConfig configures JWT label.
+type JWTConfig struct {
+	// KeepClaims stay readable. iss, sub, exp and aud are kept if empty.
+	KeepClaims []string
+	// MaskClaims are masked with the labels, e.g. {"email": MaskerLabelEmail}. The claims which are neither kept nor
+	// listed here are masked with PASSWORD label. MaskClaims take precedence over KeepClaims.
+	MaskClaims map[string]MaskerLabel
+}
+
+// isZero reports whether the config is not specified.
+func (c JWTConfig) isZero() bool {
+	return len(c.KeepClaims) == 0 && len(c.MaskClaims) == 0
+}
+
+// jwtMasker masks JWT. labels is used to look up the labels of masked claims.
+type jwtMasker struct {
+	keep       map[string]bool
+	maskClaims map[string]MaskerLabel
+	labels     func(MaskerLabel) (StrategyMaskerFunc, bool)
+}
+
+// newJWTMasker returns jwtMasker applying defaults to the config.
+func newJWTMasker(cfg JWTConfig, labels func(MaskerLabel) (StrategyMaskerFunc, bool)) *jwtMasker {
+	keepClaims := cfg.KeepClaims
+	if len(keepClaims) == 0 {
+		keepClaims = _defaultJWTKeepClaims
+	}
+
+	keep := make(map[string]bool, len(keepClaims))
+	for _, claim := range keepClaims {
+		keep[claim] = true
+	}
+
+	return &jwtMasker{keep: keep, maskClaims: cfg.MaskClaims, labels: labels}
+}
+
+// mask is StrategyMaskerFunc of JWT label. Strategy is applied to the values which are not JWT.
+func (j *jwtMasker) mask(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
+	raw := fmt.Sprint(value)
+
+	scheme, token := "", raw
+	if i := strings.LastIndexByte(raw, ' '); i >= 0 {
+		scheme, token = raw[:i+1], raw[i+1:]
+	}
+
+	header, claims, ok := parseJWT(token)
+	if !ok {
+		return passwordMasker(key, value, strategy)
+	}
+
+	for claim, v := range claims {
+		label, masked := j.maskClaims[claim]
+		if !masked && j.keep[claim] {
+			continue
+		}
+		if !masked {
+			label = MaskerLabelPassword
+		}
+
+		masker, ok := j.labels(label)
+		if !ok {
+			return MaskResult{}, fmt.Errorf("unknown mask label '%s' of claim '%s'", label, claim)
+		}
+
+		ret, err := masker(claim, v, nil)
+		if err != nil {
+			return MaskResult{}, fmt.Errorf("fail while masking claim '%s': %+v", claim, err)
+		}
+		claims[claim] = ret.Value
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return MaskResult{}, fmt.Errorf("failed to marshal jwt header: %+v", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return MaskResult{}, fmt.Errorf("failed to marshal jwt claims: %+v", err)
+	}
+
+	return MaskResult{Key: key, Value: scheme + string(headerJSON) + "." + string(claimsJSON)}, nil
+}
+
+// parseJWT decodes the header and the claims of the token. Numbers are decoded as json.Number.
+func parseJWT(token string) (header, claims map[string]any, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, false
+	}
+
+	if header, ok = decodeJWTPart(parts[0]); !ok {
+		return nil, nil, false
+	}
+	if claims, ok = decodeJWTPart(parts[1]); !ok {
+		return nil, nil, false
+	}
+
+	return header, claims, true
+}
+
+// decodeJWTPart decodes base64url encoded json object. Empty parts and invalid json are rejected before decoding, since
+// decodeValue expects valid input.
+func decodeJWTPart(part string) (map[string]any, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil || !json.Valid(data) {
+		return nil, false
+	}
+
+	value, err := decodeValue(data)
+	if err != nil {
+		return nil, false
+	}
+
+	obj, ok := value.(map[string]any)
+	return obj, ok
+}
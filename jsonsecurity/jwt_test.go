@@ -0,0 +1,92 @@
+package jsonsecurity
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestJWT(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"RS256","kid":"k1"}`)) + "." + encode([]byte(claims)) + ".c2lnbmF0dXJl"
+}
+
+func TestMasker_MaskWith_ShouldMaskJWT(t *testing.T) {
+	masker, err := NewMasker(Config{})
+	assert.NoError(t, err)
+
+	token := newTestJWT(`{"iss":"auth","sub":"42","aud":["api"],"exp":1700000000,"email":"john@example.com","admin":true}`)
+	want := `{"alg":"RS256","kid":"k1"}.{"admin":"****","aud":["api"],"email":"****************","exp":1700000000,` +
+		`"iss":"auth","sub":"42"}`
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: token, want: want},
+		{value: "Bearer " + token, want: "Bearer " + want},
+		{value: "Bearer qwerty", want: "*************"},
+		{value: "qwerty.qwerty.qwerty", want: "********************"},
+		{value: "eyJhIjoxfQ.e30", want: "**************"},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.MaskWith(MaskerLabelJWT, "token", tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, ret.Value, tt.value)
+	}
+}
+
+func TestMasker_MaskWith_ShouldMaskCompletely_WhenJWTPartsAreInvalid(t *testing.T) {
+	masker, err := NewMasker(Config{})
+	assert.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "..", want: "**"},
+		{value: "a..c", want: "****"},
+		{value: "Bearer ..", want: "*********"},
+		{value: encode([]byte(`"`)) + "." + encode([]byte(`{}`)) + ".c2ln", want: "***********"},
+	}
+
+	for _, tt := range tests {
+		assert.NotPanics(t, func() {
+			ret, err := masker.MaskWith(MaskerLabelJWT, "token", tt.value)
+			assert.NoError(t, err, tt.value)
+			assert.Equal(t, tt.want, ret.Value, tt.value)
+		}, tt.value)
+	}
+}
+
+func TestMasker_MaskWith_ShouldMaskConfiguredJWTClaims(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 2,
+		Triggers: map[string]TriggerOpts{
+			"authorization": {ShouldAppear: true, MaskMethod: MaskerLabelJWT},
+		},
+		JWT: JWTConfig{
+			KeepClaims: []string{"sub", "scope", "email"},
+			MaskClaims: map[string]MaskerLabel{"email": MaskerLabelEmail, "phone": MaskerLabelPhoneNumber},
+		},
+	})
+	assert.NoError(t, err)
+
+	token := newTestJWT(`{"iss":"auth","sub":"42","scope":"read","email":"john@example.com","phone":"+79161234567"}`)
+
+	ret, err := masker.Mask(`{"authorization": "Bearer ` + token + `"}`)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"authorization": "Bearer {\"alg\":\"RS256\",\"kid\":\"k1\"}.{\"email\":\"j***@example.com\",`+
+			`\"iss\":\"****\",\"phone\":\"+7******4567\",\"scope\":\"read\",\"sub\":\"42\"}"}`,
+		ret,
+	)
+}
+
+func TestNewMasker_ShouldFail_WhenJWTClaimLabelIsUnknown(t *testing.T) {
+	_, err := NewMasker(Config{JWT: JWTConfig{MaskClaims: map[string]MaskerLabel{"email": "UNKNOWN"}}})
+	assert.Error(t, err)
+}
@@ -11,129 +11,204 @@ type MaskerLabel string
 
 const MaskerLabelPassword MaskerLabel = "PASSWORD"
 
-var passwordMasker = func(key string, value interface{}) (MaskResult, error) {
+var passwordMasker = func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
 	return MaskResult{
 		Key:   key,
-		Value: strings.Repeat(MaskSymbol, len(fmt.Sprint(value))),
+		Value: _maskBetween(fmt.Sprint(value), strategy.orDefault(MaskStrategy{})),
 	}, nil
 }
 
 const MaskerLabelCVV MaskerLabel = "CVV"
 
-var cvvMasker = func(key string, value interface{}) (MaskResult, error) {
-	const mask = MaskSymbol + MaskSymbol + MaskSymbol
+var cvvMasker = func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
+	const fixedLength = 3
 
 	return MaskResult{
 		Key:   key,
-		Value: mask,
+		Value: _maskBetween(fmt.Sprint(value), strategy.orDefault(MaskStrategy{FixedLength: fixedLength})),
 	}, nil
 }
 
 const MaskerLabelName MaskerLabel = "NAME"
 
-var nameMasker = func(key string, value interface{}) (MaskResult, error) {
+var nameMasker = func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
 	const leadingLength = 1
 	const trailingLength = 1
 
-	if ret, err := _maskBetween(fmt.Sprint(value), leadingLength, trailingLength); err != nil {
-		return MaskResult{}, err
-	} else {
-		return MaskResult{
-			Key:   key,
-			Value: ret,
-		}, nil
-	}
+	return MaskResult{
+		Key: key,
+		Value: _maskBetween(
+			fmt.Sprint(value),
+			strategy.orDefault(MaskStrategy{KeepLeading: leadingLength, KeepTrailing: trailingLength}),
+		),
+	}, nil
 }
 
 const MaskerLabelCardNumber MaskerLabel = "CARD_NUMBER"
 
-var cardMasker = func(key string, value interface{}) (MaskResult, error) {
+var cardMasker = func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
 	const leadingLength = 6
 	const trailingLength = 4
 
-	if ret, err := _maskBetween(fmt.Sprint(value), leadingLength, trailingLength); err != nil {
-		return MaskResult{}, err
-	} else {
-		return MaskResult{
-			Key:   key,
-			Value: ret,
-		}, nil
-	}
+	return MaskResult{
+		Key: key,
+		Value: _maskBetween(
+			fmt.Sprint(value),
+			strategy.orDefault(MaskStrategy{KeepLeading: leadingLength, KeepTrailing: trailingLength}),
+		),
+	}, nil
 }
 
 const MaskerLabelPhoneNumber MaskerLabel = "PHONE_NUMBER"
 
-var phoneNumberMasker = func(key string, value interface{}) (MaskResult, error) {
+var phoneNumberMasker = func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
 	const leadingLength = 2
 	const trailingLength = 4
 
-	if ret, err := _maskBetween(fmt.Sprint(value), leadingLength, trailingLength); err != nil {
-		return MaskResult{}, err
-	} else {
-		return MaskResult{
-			Key:   key,
-			Value: ret,
-		}, nil
-	}
+	return MaskResult{
+		Key: key,
+		Value: _maskBetween(
+			fmt.Sprint(value),
+			strategy.orDefault(MaskStrategy{KeepLeading: leadingLength, KeepTrailing: trailingLength}),
+		),
+	}, nil
 }
 
 const MaskerLabelEmail MaskerLabel = "EMAIL"
 
-var emailMasker = func(key string, value interface{}) (MaskResult, error) {
-	var leadingLength, trailingLength int
+// emailMasker masks the local part of email keeping the domain. KeepLeading and KeepTrailing of the strategy are
+// applied to the local part.
+var emailMasker = func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
+	const leadingLength = 1
 
 	strValue := fmt.Sprint(value)
-	lenValue := len(strValue)
-
-	atIdx := strings.Index(strValue, "@")
-	if atIdx != -1 {
-		leadingLength = 1
-		trailingLength = lenValue - atIdx
-	} else {
-		leadingLength = lenValue
-	}
+	s := strategy.orDefault(MaskStrategy{KeepLeading: leadingLength})
 
-	if ret, err := _maskBetween(strValue, leadingLength, trailingLength); err != nil {
-		return MaskResult{}, err
-	} else {
-		return MaskResult{
-			Key:   key,
-			Value: ret,
-		}, nil
+	atIdx := strings.LastIndex(strValue, "@")
+	if atIdx == -1 {
+		return MaskResult{Key: key, Value: _maskBetween(strValue, s)}, nil
 	}
+
+	return MaskResult{
+		Key:   key,
+		Value: _maskBetween(strValue[:atIdx], s) + strValue[atIdx:],
+	}, nil
 }
 
 // UTILS
 
-func _maskBetween(value string, leadingLen int, trailingLen int) (string, error) {
-	var runeLeading, runeTrailing string
+// _maskBetween masks the value keeping leading and trailing characters specified in strategy. Characters are counted
+// as user-perceived ones (see graphemes), so the result is valid UTF-8 for any input. Whitespaces are kept unless
+// FixedLength is set. Values shorter than the number of kept characters are masked completely.
+func _maskBetween(value string, strategy MaskStrategy) string {
+	var leading, trailing string
 
-	lenValue := len(value)
-	lenConstraint := leadingLen + trailingLen
+	symbol := strategy.symbol()
+	chars := graphemes(value)
+	middle := chars
 
-	masked := strings.Repeat(MaskSymbol, lenValue)
-	if lenValue >= lenConstraint {
-		runeLeading, runeTrailing = value[0:leadingLen], value[lenValue-trailingLen:]
-		masked = _noWhitespaceRegexCompiled.ReplaceAllString(value[leadingLen:lenValue-trailingLen], MaskSymbol)
+	if n := len(chars); n >= strategy.KeepLeading+strategy.KeepTrailing {
+		leading = strings.Join(chars[:strategy.KeepLeading], "")
+		trailing = strings.Join(chars[n-strategy.KeepTrailing:], "")
+		middle = chars[strategy.KeepLeading : n-strategy.KeepTrailing]
 	}
 
-	return runeLeading + masked + runeTrailing, nil
+	if strategy.FixedLength > 0 {
+		return leading + strings.Repeat(symbol, strategy.FixedLength) + trailing
+	}
+
+	if leading == "" && trailing == "" {
+		return strings.Repeat(symbol, len(middle))
+	}
+
+	var b strings.Builder
+	b.Grow(len(value))
+	b.WriteString(leading)
+	for _, c := range middle {
+		if isSpaceGrapheme(c) {
+			b.WriteString(c)
+		} else {
+			b.WriteString(symbol)
+		}
+	}
+	b.WriteString(trailing)
+
+	return b.String()
 }
 
 var (
 	_mapLabelsMu sync.RWMutex
-	_mapLabels   map[MaskerLabel]MaskerFunc
+	_mapLabels   map[MaskerLabel]StrategyMaskerFunc
 )
 
-func init() {
+// RegisterLabel registers global label which can be used in any Masker. It fails when the label is already registered.
+// Labels specific to a single Masker should be passed with Config.Labels instead.
+func RegisterLabel(label MaskerLabel, masker MaskerFunc) error {
+	return RegisterStrategyLabel(label, masker.withStrategy())
+}
+
+// RegisterStrategyLabel registers global label the same way RegisterLabel does. The masker receives MaskStrategy of the
+// trigger, so the label can be parameterized like built-in ones.
+func RegisterStrategyLabel(label MaskerLabel, masker StrategyMaskerFunc) error {
+	_mapLabelsMu.Lock()
+	defer _mapLabelsMu.Unlock()
+
+	if err := validateLabelLocked(label, masker); err != nil {
+		return err
+	}
+
+	_mapLabels[label] = masker
+	return nil
+}
+
+// globalLabelMasker returns StrategyMaskerFunc registered globally for the label.
+func globalLabelMasker(label MaskerLabel) (StrategyMaskerFunc, bool) {
 	_mapLabelsMu.RLock()
-	_mapLabels = make(map[MaskerLabel]MaskerFunc, 6)
+	masker, ok := _mapLabels[label]
+	_mapLabelsMu.RUnlock()
+
+	return masker, ok
+}
+
+// validateLabel checks that the label is not empty and not registered globally yet.
+func validateLabel(label MaskerLabel, masker StrategyMaskerFunc) error {
+	_mapLabelsMu.RLock()
+	defer _mapLabelsMu.RUnlock()
+
+	return validateLabelLocked(label, masker)
+}
+
+func validateLabelLocked(label MaskerLabel, masker StrategyMaskerFunc) error {
+	if label == "" {
+		return fmt.Errorf("empty mask label")
+	}
+	if masker == nil {
+		return fmt.Errorf("nil masker func of label '%s'", label)
+	}
+	if _, ok := _mapLabels[label]; ok {
+		return fmt.Errorf("mask label '%s' is already registered", label)
+	}
+
+	return nil
+}
+
+func init() {
+	_mapLabelsMu.Lock()
+	_mapLabels = make(map[MaskerLabel]StrategyMaskerFunc, 14)
 	_mapLabels[MaskerLabelCVV] = cvvMasker
 	_mapLabels[MaskerLabelPassword] = passwordMasker
 	_mapLabels[MaskerLabelEmail] = emailMasker
 	_mapLabels[MaskerLabelName] = nameMasker
 	_mapLabels[MaskerLabelPhoneNumber] = phoneNumberMasker
 	_mapLabels[MaskerLabelCardNumber] = cardMasker
-
-	_mapLabelsMu.RUnlock()
+	_mapLabels[MaskerLabelINN] = innMasker
+	_mapLabels[MaskerLabelSNILS] = snilsMasker
+	_mapLabels[MaskerLabelPassportRU] = passportRUMasker
+	_mapLabels[MaskerLabelOGRN] = ogrnMasker
+	_mapLabels[MaskerLabelIBAN] = ibanMasker
+	_mapLabels[MaskerLabelBIC] = bicMasker
+	_mapLabels[MaskerLabelSSN] = ssnMasker
+	_mapLabels[MaskerLabelJWT] = newJWTMasker(JWTConfig{}, globalLabelMasker).mask
+
+	_mapLabelsMu.Unlock()
 }
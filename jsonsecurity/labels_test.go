@@ -0,0 +1,127 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func upperMasker(key string, value interface{}) (MaskResult, error) {
+	return MaskResult{Key: key, Value: strings.ToUpper(value.(string))}, nil
+}
+
+// unregisterLabel removes globally registered label, so the tests can be run repeatedly.
+func unregisterLabel(label MaskerLabel) {
+	_mapLabelsMu.Lock()
+	defer _mapLabelsMu.Unlock()
+
+	delete(_mapLabels, label)
+}
+
+func TestRegisterLabel_ShouldFail_WhenLabelIsDuplicated(t *testing.T) {
+	assert.NoError(t, RegisterLabel("TEST_UPPER", upperMasker))
+	t.Cleanup(func() { unregisterLabel("TEST_UPPER") })
+	assert.Error(t, RegisterLabel("TEST_UPPER", upperMasker))
+	assert.Error(t, RegisterLabel(MaskerLabelPassword, upperMasker))
+	assert.Error(t, RegisterLabel("", upperMasker))
+	assert.Error(t, RegisterLabel("TEST_NIL", nil))
+
+	masker, err := NewMasker(Config{
+		MaxDepth: 1,
+		Triggers: map[string]TriggerOpts{"name": {ShouldAppear: true, MaskMethod: "TEST_UPPER"}},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(`{"name":"john"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"JOHN"}`, ret)
+}
+
+func TestRegisterStrategyLabel_ShouldPassStrategy(t *testing.T) {
+	assert.NoError(t, RegisterStrategyLabel(
+		"TEST_PREFIX",
+		func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
+			return MaskResult{Key: key, Value: strategy.orDefault(MaskStrategy{MaskSymbol: "-"}).symbol() + value.(string)}, nil
+		},
+	))
+	t.Cleanup(func() { unregisterLabel("TEST_PREFIX") })
+	assert.Error(t, RegisterStrategyLabel("TEST_PREFIX", nil))
+
+	masker, err := NewMasker(Config{
+		MaxDepth: 1,
+		Triggers: map[string]TriggerOpts{
+			"name":  {ShouldAppear: true, MaskMethod: "TEST_PREFIX"},
+			"login": {ShouldAppear: true, MaskMethod: "TEST_PREFIX", Strategy: &MaskStrategy{MaskSymbol: "#"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(`{"name":"john","login":"jdoe"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"-john","login":"#jdoe"}`, ret)
+}
+
+func TestNewMasker_ShouldUseCustomLabels(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 1,
+		Triggers: map[string]TriggerOpts{"name": {ShouldAppear: true, MaskMethod: "LOCAL_UPPER"}},
+		Labels:   map[MaskerLabel]MaskerFunc{"LOCAL_UPPER": upperMasker},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(`{"name":"john"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"JOHN"}`, ret)
+
+	_, ok := globalLabelMasker("LOCAL_UPPER")
+	assert.False(t, ok)
+}
+
+func TestNewMasker_ShouldFail_WhenLabelIsInvalid(t *testing.T) {
+	_, err := NewMasker(Config{Labels: map[MaskerLabel]MaskerFunc{MaskerLabelEmail: upperMasker}})
+	assert.Error(t, err)
+
+	_, err = NewMasker(Config{Triggers: map[string]TriggerOpts{"name": {ShouldAppear: true, MaskMethod: "UNKNOWN"}}})
+	assert.Error(t, err)
+
+	masker, err := NewMasker(Config{})
+	assert.NoError(t, err)
+	_, err = masker.MaskWith("UNKNOWN", "name", "john")
+	assert.Error(t, err)
+}
+
+func TestMasker_ShouldApplyMaskStrategy(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 1,
+		Triggers: map[string]TriggerOpts{
+			"card":     {ShouldAppear: true, MaskMethod: MaskerLabelCardNumber},
+			"pan":      {ShouldAppear: true, MaskMethod: MaskerLabelCardNumber, Strategy: &MaskStrategy{KeepTrailing: 4, MaskSymbol: "#"}},
+			"password": {ShouldAppear: true, MaskMethod: MaskerLabelPassword, Strategy: &MaskStrategy{FixedLength: 8}},
+			"email":    {ShouldAppear: true, MaskMethod: MaskerLabelEmail, Strategy: &MaskStrategy{KeepLeading: 2, FixedLength: 3}},
+			"cvv":      {ShouldAppear: true, MaskMethod: MaskerLabelCVV},
+			"secret":   {ShouldAppear: false, Replacement: RedactedValue},
+			"token":    {ShouldAppear: false},
+		},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(`{"card":"4111111111111111","pan":"4111111111111111","password":"qwerty","email":"john.doe@example.com","cvv":"1234","secret":"s","token":"t"}`)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"card":"411111******1111","pan":"############1111","password":"********","email":"jo***@example.com","cvv":"***","secret":"[REDACTED]"}`,
+		ret,
+	)
+}
+
+func TestNewMasker_ShouldKeepValue_WhenMaskMethodIsEmpty(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 1,
+		Triggers: map[string]TriggerOpts{"name": {ShouldAppear: true}, "password": {ShouldAppear: false}},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(`{"name":"john","password":"qwerty"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"john"}`, ret)
+}
@@ -1,6 +1,7 @@
 package jsonsecurity
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -12,10 +13,57 @@ type Masker struct {
 	cfg Config
 
 	lowercaseTriggers map[string][]TriggerOpts
+	// pathTriggers and patternTriggers are compiled Config.PathTriggers and Config.PatternTriggers.
+	pathTriggers    []pathTrigger
+	patternTriggers []patternTrigger
+	// detectors are value-based detectors enabled by Config.Detection.
+	detectors         []detector
+	detectionSkipKeys map[string]struct{}
+	// allowlist is compiled Config.Allowlist. It's nil when default-deny mode is disabled.
+	allowlist *allowlist
+	// redactor is compiled Config.Redaction. It's nil when the redaction is disabled.
+	redactor *redactor
+	// labels stores the labels from Config.Labels. They're looked up before global ones.
+	labels map[MaskerLabel]StrategyMaskerFunc
 }
 
-// NewMasker returns a new Masker instance.
+// NewMasker returns a new Masker instance. It fails when Config.Labels duplicate global labels or when triggers refer
+// to unknown labels.
 func NewMasker(cfg Config) (*Masker, error) {
+	labels := make(map[MaskerLabel]StrategyMaskerFunc, len(cfg.Labels))
+	for label, masker := range cfg.Labels {
+		fn := masker.withStrategy()
+		if err := validateLabel(label, fn); err != nil {
+			return nil, err
+		}
+		labels[label] = fn
+	}
+
+	hasher, err := newHasher(cfg.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptor, err := newEncryptor(cfg.Encrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Labels provided by config sections.
+	provided := make(map[MaskerLabel]StrategyMaskerFunc, 2)
+	if hasher != nil {
+		provided[MaskerLabelHash] = hasher.mask
+	}
+	if encryptor != nil {
+		provided[MaskerLabelEncrypt] = encryptor.mask
+	}
+	for label, masker := range provided {
+		if _, ok := labels[label]; ok {
+			return nil, fmt.Errorf("mask label '%s' is provided by config", label)
+		}
+		labels[label] = masker
+	}
+
 	lowercaseTriggers := make(map[string][]TriggerOpts, 0)
 	for k, v := range cfg.Triggers {
 		lowerK := strings.ToLower(k)
@@ -28,13 +76,80 @@ func NewMasker(cfg Config) (*Masker, error) {
 		}
 	}
 
-	return &Masker{
+	pathTriggers, err := compilePathTriggers(cfg.PathTriggers)
+	if err != nil {
+		return nil, err
+	}
+
+	patternTriggers, err := compilePatternTriggers(cfg.PatternTriggers)
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist, err := compileAllowlist(cfg.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	var detectors []detector
+	if cfg.Detection.Enabled {
+		if detectors, err = compileDetectors(cfg.Detection.Detectors); err != nil {
+			return nil, err
+		}
+	}
+
+	redactor, err := compileRedactor(cfg.Redaction)
+	if err != nil {
+		return nil, err
+	}
+
+	detectionSkipKeys := make(map[string]struct{}, len(cfg.Detection.SkipKeys))
+	for _, k := range cfg.Detection.SkipKeys {
+		detectionSkipKeys[strings.ToLower(k)] = struct{}{}
+	}
+
+	m := &Masker{
 		cfg:               cfg,
 		lowercaseTriggers: lowercaseTriggers,
-	}, nil
+		pathTriggers:      pathTriggers,
+		patternTriggers:   patternTriggers,
+		detectors:         detectors,
+		detectionSkipKeys: detectionSkipKeys,
+		allowlist:         allowlist,
+		redactor:          redactor,
+		labels:            labels,
+	}
+
+	if !cfg.JWT.isZero() {
+		labels[MaskerLabelJWT] = newJWTMasker(cfg.JWT, m.labelMasker).mask
+		for claim, label := range cfg.JWT.MaskClaims {
+			if _, ok := m.labelMasker(label); !ok {
+				return nil, fmt.Errorf("unknown mask label '%s' of jwt claim '%s'", label, claim)
+			}
+		}
+	}
+
+	for _, triggers := range []map[string]TriggerOpts{
+		cfg.Triggers, cfg.PathTriggers, cfg.PatternTriggers, cfg.Redaction.Patterns,
+	} {
+		for k, v := range triggers {
+			if _, ok := m.labelMasker(v.MaskMethod); v.ShouldAppear && !ok {
+				return nil, fmt.Errorf("unknown mask label '%s' of trigger '%s'", v.MaskMethod, k)
+			}
+		}
+	}
+
+	if allowlist != nil {
+		if _, ok := m.labelMasker(allowlist.label); !ok {
+			return nil, fmt.Errorf("unknown mask label '%s' of allowlist", allowlist.label)
+		}
+	}
+
+	return m, nil
 }
 
-// Mask masks data given in parameters using specified config.
+// Mask masks data given in parameters using specified config. The data is masked token by token, so the order of keys,
+// number literals and whitespaces are preserved. See MaskBytes and MaskStream.
 //
 // Example:
 //
@@ -44,64 +159,99 @@ func NewMasker(cfg Config) (*Masker, error) {
 //
 // Output = `{"password": "*********", "email": "e******@example.com"}`
 func (m *Masker) Mask(data string) (string, error) {
-	var dataAny any
-
-	err := json.Unmarshal([]byte(data), &dataAny)
+	masked, err := m.MaskBytes([]byte(data))
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal initial data: %+v", err)
+		return "", err
 	}
 
-	result, err := m.walkthrough(dataAny, 0)
+	return string(masked), nil
+}
+
+// MaskValue marshals value to json and masks it the same way Mask does. The result is unmarshalled back, so it can be
+// logged as a structure instead of escaped string. Numbers are unmarshalled as json.Number to keep their precision.
+// Errors and fmt.Stringer values which don't marshal themselves are masked as their text, otherwise most of them
+// would be marshalled to `{}`.
+func (m *Masker) MaskValue(value any) (any, error) {
+	data, err := json.Marshal(textValue(value))
 	if err != nil {
-		return "", fmt.Errorf("walkthrough error: %+v", err)
+		return nil, fmt.Errorf("failed to marshal initial value: %+v", err)
 	}
 
-	bytes, err := json.Marshal(result)
+	masked, err := m.MaskBytes(data)
 	if err != nil {
-		return "", fmt.Errorf("fail while marshalling result map: %+v", err)
+		return nil, err
 	}
 
-	return string(bytes), nil
+	return decodeValue(masked)
 }
 
-// walkthrough falls down to the json structure considering every key-value pair may be represented as map[string]interface{}
-// It gets trigger options in config using key and replaces initial value using the value from walkthrough result.
-func (m *Masker) walkthrough(layer interface{}, currentDepth int) (interface{}, error) {
-	if currentDepth > m.cfg.MaxDepth {
-		return nil, fmt.Errorf("max recursion depth reached: %d", m.cfg.MaxDepth)
+// textValue returns the text of errors and fmt.Stringer values unless they implement json.Marshaler or
+// encoding.TextMarshaler (or are json.Number). The other values are returned as is.
+func textValue(value any) any {
+	switch value.(type) {
+	case json.Number, json.Marshaler, encoding.TextMarshaler:
+		return value
 	}
 
-	switch t := layer.(type) {
-	case map[string]interface{}:
-		for k, v := range t {
-			var err error
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
 
-			triggerOpt, ok := m.getTriggerOpts(k)
-			if !ok {
-				t[k], err = m.walkthrough(v, currentDepth+1)
-				if err != nil {
-					return nil, fmt.Errorf("fail while walking through key '%s', depth '%d': %+v", k, currentDepth, err)
-				}
-				continue
-			}
+	return value
+}
 
-			err = mask(t, k, triggerOpt)
-			if err != nil {
-				return nil, fmt.Errorf("fail while masking key '%s', depth '%d': %+v", k, currentDepth, err)
-			}
+// ShouldMaskAny reports whether values of field.Any should be masked with MaskValue. Nil masker doesn't mask them.
+func (m *Masker) ShouldMaskAny() bool {
+	return m != nil && m.cfg.MaskAny
+}
+
+// findTrigger returns TriggerOpts for the value located at path. Path triggers take precedence over exact key
+// triggers, pattern triggers are checked last.
+func (m *Masker) findTrigger(path []pathElem) (TriggerOpts, bool) {
+	if trigger, ok := m.findPathTrigger(path); ok {
+		return trigger, true
+	}
+
+	last := path[len(path)-1]
+	if last.isIndex {
+		return TriggerOpts{}, false
+	}
+
+	if trigger, ok := m.getTriggerOpts(last.key); ok {
+		return trigger, true
+	}
+
+	for _, trigger := range m.patternTriggers {
+		if trigger.regex.MatchString(last.key) {
+			return trigger.opts, true
 		}
-	case []interface{}:
-		for i, v := range t {
-			var err error
+	}
 
-			t[i], err = m.walkthrough(v, currentDepth+1)
-			if err != nil {
-				return nil, fmt.Errorf("fail while masking array index '%d', depth '%d': %+v", i, currentDepth, err)
-			}
+	return TriggerOpts{}, false
+}
+
+// findPathTrigger returns TriggerOpts of the first path trigger matching the path.
+func (m *Masker) findPathTrigger(path []pathElem) (TriggerOpts, bool) {
+	for _, trigger := range m.pathTriggers {
+		if matchPath(trigger.segments, path, trigger.opts.CaseSensitive) {
+			return trigger.opts, true
 		}
-	default:
 	}
-	return layer, nil
+
+	return TriggerOpts{}, false
+}
+
+// Trigger returns TriggerOpts configured for the top-level key if any. Path, exact key and pattern triggers are
+// checked. Nil masker has no triggers.
+func (m *Masker) Trigger(key string) (TriggerOpts, bool) {
+	if m == nil {
+		return TriggerOpts{}, false
+	}
+
+	return m.findTrigger([]pathElem{{key: key}})
 }
 
 // getTriggerOpts returns TriggerOpts for specified label-string.
@@ -120,30 +270,55 @@ func (m *Masker) getTriggerOpts(key string) (TriggerOpts, bool) {
 	return TriggerOpts{}, false
 }
 
-// mask masks the key-value pair located in dict using provided TriggerOpts.
-func mask(dict map[string]interface{}, key string, opts TriggerOpts) error {
+// MaskWith masks a single key-value pair using the masking function registered for label either in Config.Labels or
+// globally with RegisterLabel. Default parameters of the label are used. Unknown labels result in error.
+func (m *Masker) MaskWith(label MaskerLabel, key string, value interface{}) (MaskResult, error) {
+	return m.maskWithStrategy(label, key, value, nil)
+}
+
+// MaskTrigger masks a single key-value pair using provided TriggerOpts. ok is false when the pair must be removed, i.e.
+// ShouldAppear is false and there is no Replacement.
+func (m *Masker) MaskTrigger(opts TriggerOpts, key string, value interface{}) (ret MaskResult, ok bool, err error) {
 	if !opts.ShouldAppear {
-		delete(dict, key)
-		return nil
+		if opts.Replacement == "" {
+			return MaskResult{}, false, nil
+		}
+		return MaskResult{Key: key, Value: opts.Replacement}, true, nil
 	}
 
-	var err error
-
-	_mapLabelsMu.RLock()
-	masker, ok := _mapLabels[opts.MaskMethod]
-	_mapLabelsMu.RUnlock()
+	ret, err = m.maskWithStrategy(opts.MaskMethod, key, value, opts.Strategy)
+	return ret, err == nil, err
+}
 
+// maskWithStrategy masks a single key-value pair using the label and strategy.
+func (m *Masker) maskWithStrategy(
+	label MaskerLabel,
+	key string,
+	value interface{},
+	strategy *MaskStrategy,
+) (MaskResult, error) {
+	masker, ok := m.labelMasker(label)
 	if !ok {
-		masker = defaultMasker
+		return MaskResult{}, fmt.Errorf("unknown mask label: '%s'", label)
 	}
 
-	ret, err := masker(key, dict[key])
-	if err != nil {
-		return err
+	return masker(key, value, strategy)
+}
+
+// labelMasker returns StrategyMaskerFunc registered for the label. Labels of the masker take precedence over global
+// ones. Empty label keeps the value as is.
+func (m *Masker) labelMasker(label MaskerLabel) (StrategyMaskerFunc, bool) {
+	if label == "" {
+		return defaultMasker, true
+	}
+
+	if m != nil {
+		if masker, ok := m.labels[label]; ok {
+			return masker, true
+		}
 	}
 
-	dict[ret.Key] = ret.Value
-	return nil
+	return globalLabelMasker(label)
 }
 
 // MaskResult represents the result of masking.
@@ -158,6 +333,22 @@ type MaskResult struct {
 // MaskerFunc masks given key-value pair and returns MaskResult structure which defines further behavior of masking algorithm.
 type MaskerFunc func(key string, value interface{}) (MaskResult, error)
 
-func defaultMasker(key string, value interface{}) (MaskResult, error) {
+// withStrategy adapts the func to StrategyMaskerFunc ignoring the strategy. Nil func stays nil.
+func (f MaskerFunc) withStrategy() StrategyMaskerFunc {
+	if f == nil {
+		return nil
+	}
+
+	return func(key string, value interface{}, _ *MaskStrategy) (MaskResult, error) {
+		return f(key, value)
+	}
+}
+
+// StrategyMaskerFunc is MaskerFunc parameterized by MaskStrategy of the trigger. strategy is nil unless it's specified
+// in TriggerOpts, the label should use its defaults in such case. Built-in labels are StrategyMaskerFunc, custom ones
+// can be registered with RegisterStrategyLabel.
+type StrategyMaskerFunc func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error)
+
+func defaultMasker(key string, value interface{}, _ *MaskStrategy) (MaskResult, error) {
 	return MaskResult{Key: key, Value: value}, nil
 }
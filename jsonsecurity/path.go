@@ -0,0 +1,203 @@
+package jsonsecurity
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type pathSegmentKind uint8
+
+const (
+	// pathSegmentKey matches object key, e.g. `.password` or `['password']`.
+	pathSegmentKey pathSegmentKind = iota
+	// pathSegmentIndex matches array index, e.g. `[0]`.
+	pathSegmentIndex
+	// pathSegmentAny matches any object key or array index: `.*` or `[*]`.
+	pathSegmentAny
+	// pathSegmentDescent matches zero or more levels: `..`.
+	pathSegmentDescent
+)
+
+type pathSegment struct {
+	kind  pathSegmentKind
+	key   string
+	index int
+}
+
+// pathElem is an element of the path of the value being masked. It's either object key or array index.
+type pathElem struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// appendPath returns new path with elem appended. The original path is never modified.
+func appendPath(path []pathElem, elem pathElem) []pathElem {
+	ret := make([]pathElem, len(path)+1)
+	copy(ret, path)
+	ret[len(path)] = elem
+	return ret
+}
+
+// pathTrigger is a compiled path from Config.PathTriggers.
+type pathTrigger struct {
+	expr     string
+	segments []pathSegment
+	opts     TriggerOpts
+}
+
+// patternTrigger is a compiled regular expression from Config.PatternTriggers.
+type patternTrigger struct {
+	regex *regexp.Regexp
+	opts  TriggerOpts
+}
+
+// compilePathTriggers parses path expressions. More specific (longer) expressions go first.
+func compilePathTriggers(triggers map[string]TriggerOpts) ([]pathTrigger, error) {
+	ret := make([]pathTrigger, 0, len(triggers))
+	for expr, opts := range triggers {
+		segments, err := parsePath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path trigger '%s': %+v", expr, err)
+		}
+		opts.original = expr
+		ret = append(ret, pathTrigger{expr: expr, segments: segments, opts: opts})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if len(ret[i].expr) != len(ret[j].expr) {
+			return len(ret[i].expr) > len(ret[j].expr)
+		}
+		return ret[i].expr < ret[j].expr
+	})
+
+	return ret, nil
+}
+
+// compilePatternTriggers compiles regular expressions. The triggers are sorted by expression to keep the order
+// deterministic.
+func compilePatternTriggers(triggers map[string]TriggerOpts) ([]patternTrigger, error) {
+	ret := make([]patternTrigger, 0, len(triggers))
+	for expr, opts := range triggers {
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern trigger '%s': %+v", expr, err)
+		}
+		opts.original = expr
+		ret = append(ret, patternTrigger{regex: regex, opts: opts})
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].regex.String() < ret[j].regex.String() })
+
+	return ret, nil
+}
+
+// parsePath parses simplified JSONPath expression. Supported syntax: optional root `$`, `.key`, `['key']`, `[0]`,
+// `.*`, `[*]` and recursive descent `..`. Expressions without root are anchored to the root as well.
+//
+// Examples: `clientInfo.password`, `items[*].card`, `$..secret`.
+func parsePath(expr string) ([]pathSegment, error) {
+	segments := make([]pathSegment, 0)
+
+	s := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	if s == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	// Path without root may start with a key directly.
+	if s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			segments = append(segments, pathSegment{kind: pathSegmentDescent})
+			s = s[2:]
+			if s == "" {
+				return nil, fmt.Errorf("recursive descent without key")
+			}
+			if s[0] != '[' {
+				s = "." + s
+			}
+		case s[0] == '.':
+			end := strings.IndexAny(s[1:], ".[")
+			if end == -1 {
+				end = len(s) - 1
+			}
+			key := s[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key")
+			}
+			if key == "*" {
+				segments = append(segments, pathSegment{kind: pathSegmentAny})
+			} else {
+				segments = append(segments, pathSegment{kind: pathSegmentKey, key: key})
+			}
+			s = s[end+1:]
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated bracket")
+			}
+			inner := s[1:end]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{kind: pathSegmentAny})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{kind: pathSegmentKey, key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid index '%s'", inner)
+				}
+				segments = append(segments, pathSegment{kind: pathSegmentIndex, index: index})
+			}
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected character '%c'", s[0])
+		}
+	}
+
+	return segments, nil
+}
+
+// matchPath reports whether the path matches the segments.
+func matchPath(segments []pathSegment, path []pathElem, caseSensitive bool) bool {
+	if len(segments) == 0 {
+		return len(path) == 0
+	}
+
+	segment := segments[0]
+	if segment.kind == pathSegmentDescent {
+		for i := 0; i <= len(path); i++ {
+			if matchPath(segments[1:], path[i:], caseSensitive) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	elem := path[0]
+	switch segment.kind {
+	case pathSegmentKey:
+		if elem.isIndex {
+			return false
+		}
+		if caseSensitive && elem.key != segment.key || !caseSensitive && !strings.EqualFold(elem.key, segment.key) {
+			return false
+		}
+	case pathSegmentIndex:
+		if !elem.isIndex || elem.index != segment.index {
+			return false
+		}
+	}
+
+	return matchPath(segments[1:], path[1:], caseSensitive)
+}
@@ -0,0 +1,81 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParsePath_ShouldFail_WhenPathIsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "$", "a..", "a[", "a[-1]", "a[x]", "a."} {
+		_, err := parsePath(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	path := []pathElem{{key: "items"}, {index: 1, isIndex: true}, {key: "card"}}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "items[1].card", want: true},
+		{expr: "$.items[*].card", want: true},
+		{expr: "$['items'][1]['card']", want: true},
+		{expr: "$..card", want: true},
+		{expr: "$..[1].card", want: true},
+		{expr: "items.*.card", want: true},
+		{expr: "items[0].card", want: false},
+		{expr: "card", want: false},
+		{expr: "items[*]", want: false},
+		{expr: "$..items..card", want: true},
+	}
+
+	for _, tt := range tests {
+		segments, err := parsePath(tt.expr)
+		assert.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, matchPath(segments, path, true), tt.expr)
+	}
+}
+
+func TestMasker_ShouldApplyTriggersPrecedence(t *testing.T) {
+	masker, err := NewMasker(Config{
+		MaxDepth: 5,
+		Triggers: map[string]TriggerOpts{
+			"password": {ShouldAppear: true, MaskMethod: MaskerLabelPassword},
+		},
+		PathTriggers: map[string]TriggerOpts{
+			"settings.password":  {ShouldAppear: true, MaskMethod: MaskerLabelName},
+			"items[*].card":      {ShouldAppear: true, MaskMethod: MaskerLabelCardNumber},
+			"$..secret":          {ShouldAppear: false},
+			"list[0]":            {ShouldAppear: false, Replacement: RedactedValue},
+			"CaseSensitive.path": {ShouldAppear: false, CaseSensitive: true},
+		},
+		PatternTriggers: map[string]TriggerOpts{
+			`(?i)_token$`: {ShouldAppear: true, MaskMethod: MaskerLabelPassword},
+			`^password`:   {ShouldAppear: true, MaskMethod: MaskerLabelCVV},
+		},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(`{
+		"clientInfo": {"password": "qwerty", "card": "4111111111111111"},
+		"settings": {"password": "qwerty", "password_policy": "strict"},
+		"items": [{"card": "4111111111111111"}],
+		"deep": {"deeper": {"secret": 1}},
+		"list": ["a", "b"],
+		"casesensitive": {"path": "keep"},
+		"access_TOKEN": "abc"
+	}`)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"clientInfo": {"password": "******", "card": "4111111111111111"},
+		"settings": {"password": "q****y", "password_policy": "***"},
+		"items": [{"card": "411111******1111"}],
+		"deep": {"deeper": {}},
+		"list": ["[REDACTED]", "b"],
+		"casesensitive": {"path": "keep"},
+		"access_TOKEN": "***"
+	}`, ret)
+}
@@ -0,0 +1,151 @@
+package jsonsecurity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RedactionConfig configures masking of PII in free text. See Masker.Redact.
+type RedactionConfig struct {
+	// Enabled turns the redaction on.
+	Enabled bool
+	// Patterns are regular expressions matched against the text. The match is masked using TriggerOpts, only the first
+	// capturing group is masked if the expression has one, e.g. `token=(\S+)`. Matches which shouldn't appear are
+	// replaced with Replacement or RedactedValue.
+	Patterns map[string]TriggerOpts
+	// Detectors to use in addition to Patterns. All built-in detectors except explicit ones (see DetectorBIC) are used if
+	// empty.
+	Detectors []Detector
+}
+
+// redactor is compiled RedactionConfig.
+type redactor struct {
+	patterns  []patternTrigger
+	detectors []detector
+}
+
+// redactMatch is a part of the text to be masked.
+type redactMatch struct {
+	start, end int
+	opts       TriggerOpts
+}
+
+// compileRedactor compiles the config. It returns nil if the redaction is disabled.
+func compileRedactor(cfg RedactionConfig) (*redactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	patterns, err := compilePatternTriggers(cfg.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	detectors, err := compileDetectors(cfg.Detectors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redactor{patterns: patterns, detectors: detectors}, nil
+}
+
+// ShouldRedact reports whether free text should be redacted with Redact. Nil masker doesn't redact.
+func (m *Masker) ShouldRedact() bool {
+	return m != nil && m.redactor != nil
+}
+
+// Redact masks PII found in free text by Config.Redaction patterns and detectors. Patterns take precedence over
+// detectors, overlapping matches are skipped. Parts which failed to be masked are replaced with RedactedValue, so the
+// raw value never leaks. The text is returned as is if the redaction is disabled or the masker is nil.
+//
+// Example:
+//
+// Text = `login failed for john.doe@example.com`
+//
+// Output = `login failed for j*******@example.com`
+func (m *Masker) Redact(text string) string {
+	if m == nil || m.redactor == nil || text == "" {
+		return text
+	}
+
+	matches := m.redactor.find(text)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+
+	last := 0
+	for _, match := range matches {
+		b.WriteString(text[last:match.start])
+		b.WriteString(m.redactMatch(text[match.start:match.end], match.opts))
+		last = match.end
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+// redactMatch masks a single match.
+func (m *Masker) redactMatch(value string, opts TriggerOpts) string {
+	ret, ok, err := m.MaskTrigger(opts, "", value)
+	if err != nil || !ok {
+		return RedactedValue
+	}
+
+	return fmt.Sprint(ret.Value)
+}
+
+// find returns non-overlapping matches sorted by position.
+func (r *redactor) find(text string) []redactMatch {
+	candidates := make([]redactMatch, 0)
+
+	for _, pattern := range r.patterns {
+		for _, loc := range pattern.regex.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) > 2 && loc[2] != -1 {
+				start, end = loc[2], loc[3]
+			}
+			if start != end {
+				candidates = append(candidates, redactMatch{start: start, end: end, opts: pattern.opts})
+			}
+		}
+	}
+
+	for _, d := range r.detectors {
+		for _, loc := range d.regex.FindAllStringIndex(text, -1) {
+			if d.validate != nil && !d.validate(text[loc[0]:loc[1]]) {
+				continue
+			}
+			candidates = append(candidates, redactMatch{
+				start: loc[0],
+				end:   loc[1],
+				opts:  TriggerOpts{ShouldAppear: true, MaskMethod: d.label},
+			})
+		}
+	}
+
+	// Candidates are ordered by priority, so the first one wins in case of overlapping.
+	matches := make([]redactMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !overlaps(matches, candidate) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	return matches
+}
+
+// overlaps reports whether the match overlaps any of matches.
+func overlaps(matches []redactMatch, match redactMatch) bool {
+	for _, m := range matches {
+		if match.start < m.end && m.start < match.end {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMasker_Redact(t *testing.T) {
+	masker, err := NewMasker(Config{
+		Redaction: RedactionConfig{
+			Enabled: true,
+			Patterns: map[string]TriggerOpts{
+				`token=(\S+)`:   {ShouldAppear: true, MaskMethod: MaskerLabelPassword},
+				`secret:\s*\w+`: {ShouldAppear: false},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "", want: ""},
+		{text: "nothing to hide", want: "nothing to hide"},
+		{text: "login failed for john.doe@example.com", want: "login failed for j*******@example.com"},
+		{text: "card 4111111111111111, phone +79161234567", want: "card 411111******1111, phone +7******4567"},
+		{text: "order 4111111111111112", want: "order 4111111111111112"},
+		{text: "iban GB82WEST12345698765432 ok", want: "iban GB82**************5432 ok"},
+		{text: "token=abc123 sent", want: "token=****** sent"},
+		{text: "token=john@example.com", want: "token=****************"},
+		{text: "secret: qwerty", want: RedactedValue},
+		{text: "bearer " + testJWT, want: "bearer " + maskPassword(testJWT)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, masker.Redact(tt.text), tt.text)
+	}
+}
+
+func TestMasker_Redact_ShouldReturnText_WhenDisabled(t *testing.T) {
+	masker, err := NewMasker(Config{})
+	assert.NoError(t, err)
+
+	assert.False(t, masker.ShouldRedact())
+	assert.Equal(t, "john@example.com", masker.Redact("john@example.com"))
+}
+
+func TestMasker_Redact_ShouldReturnText_WhenMaskerIsNil(t *testing.T) {
+	var masker *Masker
+
+	assert.NotPanics(t, func() {
+		assert.False(t, masker.ShouldRedact())
+		assert.False(t, masker.ShouldMaskAny())
+		assert.Equal(t, "john@example.com", masker.Redact("john@example.com"))
+	})
+}
+
+func TestNewMasker_ShouldFail_WhenRedactionIsInvalid(t *testing.T) {
+	_, err := NewMasker(Config{Redaction: RedactionConfig{
+		Enabled:  true,
+		Patterns: map[string]TriggerOpts{`(`: {ShouldAppear: true, MaskMethod: MaskerLabelPassword}},
+	}})
+	assert.Error(t, err)
+
+	_, err = NewMasker(Config{Redaction: RedactionConfig{
+		Enabled:  true,
+		Patterns: map[string]TriggerOpts{`x`: {ShouldAppear: true, MaskMethod: "UNKNOWN"}},
+	}})
+	assert.Error(t, err)
+}
@@ -1,5 +0,0 @@
-package jsonsecurity
-
-import "regexp"
-
-var _noWhitespaceRegexCompiled = regexp.MustCompile(`\S`)
@@ -0,0 +1,210 @@
+package jsonsecurity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Regional labels mask national identifiers keeping their separators, e.g. SNILS `123-456-789 64` is masked as
+// `***-***-*** 64`. KeepLeading and KeepTrailing of the strategy count letters and digits only.
+
+// MaskerLabelINN masks russian taxpayer number (10 digits for organizations, 12 digits for individuals).
+const MaskerLabelINN MaskerLabel = "INN"
+
+var innMasker = significantMasker(MaskStrategy{KeepLeading: 2, KeepTrailing: 2})
+
+// MaskerLabelSNILS masks russian individual insurance account number, e.g. 123-456-789 64.
+const MaskerLabelSNILS MaskerLabel = "SNILS"
+
+var snilsMasker = significantMasker(MaskStrategy{KeepTrailing: 2})
+
+// MaskerLabelPassportRU masks series and number of russian internal passport, e.g. 45 09 123456.
+const MaskerLabelPassportRU MaskerLabel = "PASSPORT_RU"
+
+var passportRUMasker = significantMasker(MaskStrategy{KeepLeading: 2, KeepTrailing: 2})
+
+// MaskerLabelOGRN masks russian primary state registration number (13 digits for organizations, 15 digits for
+// individual entrepreneurs).
+const MaskerLabelOGRN MaskerLabel = "OGRN"
+
+var ogrnMasker = significantMasker(MaskStrategy{KeepLeading: 3, KeepTrailing: 2})
+
+// MaskerLabelIBAN masks international bank account number keeping country code with check digits and the last 4
+// characters, e.g. DE89 **** **** **** **30 00.
+const MaskerLabelIBAN MaskerLabel = "IBAN"
+
+var ibanMasker = significantMasker(MaskStrategy{KeepLeading: 4, KeepTrailing: 4})
+
+// MaskerLabelBIC masks bank identifier code (SWIFT) keeping bank and country codes.
+const MaskerLabelBIC MaskerLabel = "BIC"
+
+var bicMasker = significantMasker(MaskStrategy{KeepLeading: 6})
+
+// MaskerLabelSSN masks US social security number keeping the last 4 digits, e.g. ***-**-6789.
+const MaskerLabelSSN MaskerLabel = "SSN"
+
+var ssnMasker = significantMasker(MaskStrategy{KeepTrailing: 4})
+
+// significantMasker returns StrategyMaskerFunc masking letters and digits of the value with default strategy def.
+func significantMasker(def MaskStrategy) StrategyMaskerFunc {
+	return func(key string, value interface{}, strategy *MaskStrategy) (MaskResult, error) {
+		return MaskResult{
+			Key:   key,
+			Value: _maskSignificant(fmt.Sprint(value), strategy.orDefault(def)),
+		}, nil
+	}
+}
+
+// _maskSignificant masks letters and digits of the value keeping separators (spaces, dashes, etc.). KeepLeading and
+// KeepTrailing count letters and digits only. Separators of the masked part are removed if FixedLength is set. Values
+// shorter than the number of kept characters are masked completely.
+func _maskSignificant(value string, strategy MaskStrategy) string {
+	symbol := strategy.symbol()
+	chars := graphemes(value)
+
+	total := 0
+	for _, c := range chars {
+		if isSignificantGrapheme(c) {
+			total++
+		}
+	}
+
+	leading, trailing := strategy.KeepLeading, strategy.KeepTrailing
+	if total < leading+trailing {
+		leading, trailing = 0, 0
+	}
+
+	var b strings.Builder
+	b.Grow(len(value))
+
+	seen, fixed := 0, false
+	for _, c := range chars {
+		kept := seen < leading || seen >= total-trailing
+		if !isSignificantGrapheme(c) {
+			// Separators between kept parts are always written.
+			if strategy.FixedLength == 0 || seen < leading || seen > total-trailing {
+				b.WriteString(c)
+			}
+			continue
+		}
+		seen++
+
+		switch {
+		case kept:
+			b.WriteString(c)
+		case strategy.FixedLength == 0:
+			b.WriteString(symbol)
+		case !fixed:
+			b.WriteString(strings.Repeat(symbol, strategy.FixedLength))
+			fixed = true
+		}
+	}
+
+	return b.String()
+}
+
+// isSignificantGrapheme reports whether the character is a letter or a digit.
+func isSignificantGrapheme(g string) bool {
+	r, _ := utf8.DecodeRuneInString(g)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// onlyDigits returns digits of the value ignoring spaces and dashes. ok is false if the value contains other
+// characters.
+func onlyDigits(value string) (digits []int, ok bool) {
+	digits = make([]int, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case isDigit(c):
+			digits = append(digits, int(c-'0'))
+		case c == ' ' || c == '-':
+		default:
+			return nil, false
+		}
+	}
+
+	return digits, true
+}
+
+// weightedSum returns sum of digits multiplied by weights.
+func weightedSum(digits []int, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += digits[i] * w
+	}
+	return sum
+}
+
+// isINN validates check digits of INN.
+func isINN(value string) bool {
+	d, ok := onlyDigits(value)
+	if !ok {
+		return false
+	}
+
+	switch len(d) {
+	case 10:
+		return weightedSum(d, []int{2, 4, 10, 3, 5, 9, 4, 6, 8})%11%10 == d[9]
+	case 12:
+		return weightedSum(d, []int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8})%11%10 == d[10] &&
+			weightedSum(d, []int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8})%11%10 == d[11]
+	default:
+		return false
+	}
+}
+
+// isSNILS validates check number of SNILS.
+func isSNILS(value string) bool {
+	d, ok := onlyDigits(value)
+	if !ok || len(d) != 11 {
+		return false
+	}
+
+	sum := weightedSum(d, []int{9, 8, 7, 6, 5, 4, 3, 2, 1})
+	if sum > 101 {
+		sum %= 101
+	}
+	if sum == 100 || sum == 101 {
+		sum = 0
+	}
+
+	return sum == d[9]*10+d[10]
+}
+
+// isOGRN validates check digit of OGRN (13 digits) and OGRNIP (15 digits).
+func isOGRN(value string) bool {
+	d, ok := onlyDigits(value)
+	if !ok || len(d) != 13 && len(d) != 15 {
+		return false
+	}
+
+	digits := strings.ReplaceAll(strings.ReplaceAll(value, " ", ""), "-", "")
+	number, err := strconv.ParseInt(digits[:len(digits)-1], 10, 64)
+	if err != nil {
+		return false
+	}
+
+	modulo := int64(11)
+	if len(d) == 15 {
+		modulo = 13
+	}
+
+	return int(number%modulo%10) == d[len(d)-1]
+}
+
+// isSSN validates structure of SSN: area is not 000, 666 or 900-999, group is not 00 and serial is not 0000.
+func isSSN(value string) bool {
+	d, ok := onlyDigits(value)
+	if !ok || len(d) != 9 {
+		return false
+	}
+
+	area := d[0]*100 + d[1]*10 + d[2]
+	group := d[3]*10 + d[4]
+	serial := d[5]*1000 + d[6]*100 + d[7]*10 + d[8]
+
+	return area != 0 && area != 666 && area < 900 && group != 0 && serial != 0
+}
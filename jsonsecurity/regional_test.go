@@ -0,0 +1,133 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegionalValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(string) bool
+		valid    []string
+		invalid  []string
+	}{
+		{
+			name:     "INN",
+			validate: isINN,
+			valid:    []string{"7707083893", "500100732259"},
+			invalid:  []string{"7707083894", "500100732258", "12345", "77070838931"},
+		},
+		{
+			name:     "SNILS",
+			validate: isSNILS,
+			valid:    []string{"112-233-445 95", "11223344595"},
+			invalid:  []string{"112-233-445 96", "112-233-445"},
+		},
+		{
+			name:     "OGRN",
+			validate: isOGRN,
+			valid:    []string{"1027700132195", "304500116000157"},
+			invalid:  []string{"1027700132196", "304500116000158", "102770013219"},
+		},
+		{
+			name:     "SSN",
+			validate: isSSN,
+			valid:    []string{"123-45-6789"},
+			invalid:  []string{"000-45-6789", "666-45-6789", "900-45-6789", "123-00-6789", "123-45-0000", "123-45-678"},
+		},
+		{
+			name:     "IBAN",
+			validate: isIBAN,
+			valid:    []string{"DE89 3704 0044 0532 0130 00", "GB82WEST12345698765432"},
+			invalid:  []string{"DE88 3704 0044 0532 0130 00"},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, value := range tt.valid {
+			assert.True(t, tt.validate(value), "%s %s", tt.name, value)
+		}
+		for _, value := range tt.invalid {
+			assert.False(t, tt.validate(value), "%s %s", tt.name, value)
+		}
+	}
+}
+
+func TestRegionalLabels(t *testing.T) {
+	tests := []struct {
+		label    MaskerLabel
+		value    interface{}
+		strategy *MaskStrategy
+		want     string
+	}{
+		{label: MaskerLabelINN, value: "7707083893", want: "77******93"},
+		{label: MaskerLabelINN, value: 500100732259, want: "50********59"},
+		{label: MaskerLabelSNILS, value: "112-233-445 95", want: "***-***-*** 95"},
+		{label: MaskerLabelPassportRU, value: "45 09 123456", want: "45 ** ****56"},
+		{label: MaskerLabelOGRN, value: "1027700132195", want: "102********95"},
+		{label: MaskerLabelIBAN, value: "DE89 3704 0044 0532 0130 00", want: "DE89 **** **** **** **30 00"},
+		{label: MaskerLabelBIC, value: "SABRRUMM", want: "SABRRU**"},
+		{label: MaskerLabelSSN, value: "123-45-6789", want: "***-**-6789"},
+		{label: MaskerLabelSSN, value: "12", want: "**"},
+		{
+			label:    MaskerLabelIBAN,
+			value:    "DE89 3704 0044 0532 0130 00",
+			strategy: &MaskStrategy{KeepLeading: 2, FixedLength: 4},
+			want:     "DE****",
+		},
+		{
+			label:    MaskerLabelPassportRU,
+			value:    "45 09 123456",
+			strategy: &MaskStrategy{KeepLeading: 4, KeepTrailing: 2, FixedLength: 3},
+			want:     "45 09***56",
+		},
+	}
+
+	masker, err := NewMasker(Config{})
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		ret, err := masker.maskWithStrategy(tt.label, "key", tt.value, tt.strategy)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, ret.Value, "%s %v", tt.label, tt.value)
+	}
+}
+
+func TestMasker_ShouldDetectRegionalValues(t *testing.T) {
+	data := `{"inn":7707083893,"snils":"112-233-445 95","ogrn":"1027700132195","ssn":"123-45-6789",` +
+		`"bic":"SABRRUMM","passport":"4509 123456","bad_inn":"7707083894"}`
+
+	masker, err := NewMasker(Config{
+		MaxDepth:  2,
+		Detection: DetectionConfig{Enabled: true},
+	})
+	assert.NoError(t, err)
+
+	ret, err := masker.Mask(data)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"inn":7707083893,"snils":"112-233-445 95","ogrn":"1027700132195","ssn":"***-**-6789",`+
+		`"bic":"SABRRUMM","passport":"4509 123456","bad_inn":"7707083894"}`, ret)
+
+	// 1700000008 is a unix timestamp having valid INN check digit.
+	ret, err = masker.Mask(`{"created_at":1700000008}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"created_at":1700000008}`, ret)
+
+	masker, err = NewMasker(Config{
+		MaxDepth:  2,
+		Detection: DetectionConfig{Enabled: true, Detectors: []Detector{DetectorINN, DetectorSNILS, DetectorOGRN}},
+	})
+	assert.NoError(t, err)
+
+	ret, err = masker.Mask(data)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"inn":"77******93","snils":"***-***-*** 95","ogrn":"102********95","ssn":"123-45-6789",`+
+		`"bic":"SABRRUMM","passport":"4509 123456","bad_inn":"7707083894"}`, ret)
+
+	masker, err = NewMasker(Config{
+		Redaction: RedactionConfig{Enabled: true, Detectors: []Detector{DetectorBIC, DetectorPassportRU}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "bic SABRRU**, passport 45** ****56", masker.Redact("bic SABRRUMM, passport 4509 123456"))
+}
@@ -0,0 +1,311 @@
+package jsonsecurity
+
+import (
+	"fmt"
+	"io"
+)
+
+// scannerChunkSize is a size of the chunk read from io.Reader at once.
+const scannerChunkSize = 32 * 1024
+
+// scanner reads raw json tokens either from byte slice or from io.Reader. It doesn't decode the tokens, their raw
+// bytes are appended to the destination instead, so the original representation is kept as is.
+type scanner struct {
+	r   io.Reader
+	buf []byte
+	pos int
+	// offset is the offset of buf[0] in the input.
+	offset int
+	// err is the error returned by r. It's io.EOF when the input is read completely.
+	err error
+}
+
+// newBytesScanner returns scanner reading data.
+func newBytesScanner(data []byte) *scanner {
+	return &scanner{buf: data, err: io.EOF}
+}
+
+// newReaderScanner returns scanner reading r.
+func newReaderScanner(r io.Reader) *scanner {
+	return &scanner{r: r, buf: make([]byte, 0, scannerChunkSize)}
+}
+
+// fill reads the next chunk if the current one is consumed. It reports whether there are unread bytes.
+func (s *scanner) fill() bool {
+	for s.pos >= len(s.buf) {
+		if s.err != nil {
+			return false
+		}
+
+		s.offset += len(s.buf)
+		s.pos = 0
+
+		var n int
+		n, s.err = s.r.Read(s.buf[:cap(s.buf)])
+		s.buf = s.buf[:n]
+	}
+
+	return true
+}
+
+// peek returns the next byte without consuming it.
+func (s *scanner) peek() (byte, bool) {
+	if !s.fill() {
+		return 0, false
+	}
+	return s.buf[s.pos], true
+}
+
+// next consumes the next byte.
+func (s *scanner) next() (byte, bool) {
+	if !s.fill() {
+		return 0, false
+	}
+	c := s.buf[s.pos]
+	s.pos++
+	return c, true
+}
+
+// errUnexpected returns an error describing unexpected end of input or unexpected character.
+func (s *scanner) errUnexpected(context string) error {
+	c, ok := s.peek()
+	switch {
+	case !ok && s.err != io.EOF:
+		return fmt.Errorf("failed to read input: %+v", s.err)
+	case !ok:
+		return fmt.Errorf("unexpected end of JSON input")
+	default:
+		return fmt.Errorf("invalid character %q %s at offset %d", c, context, s.offset+s.pos)
+	}
+}
+
+// expect consumes the byte c.
+func (s *scanner) expect(c byte, context string) error {
+	if next, ok := s.peek(); !ok || next != c {
+		return s.errUnexpected(context)
+	}
+	s.pos++
+	return nil
+}
+
+// whitespace appends whitespaces to dst.
+func (s *scanner) whitespace(dst []byte) []byte {
+	for {
+		c, ok := s.peek()
+		if !ok || !isSpace(c) {
+			return dst
+		}
+		dst = append(dst, c)
+		s.pos++
+	}
+}
+
+// eof checks that the input is read completely.
+func (s *scanner) eof() error {
+	if _, ok := s.peek(); ok {
+		return s.errUnexpected("after top-level value")
+	}
+	if s.err != io.EOF {
+		return fmt.Errorf("failed to read input: %+v", s.err)
+	}
+	return nil
+}
+
+// value appends the raw value including nested ones to dst.
+func (s *scanner) value(dst []byte) ([]byte, error) {
+	c, ok := s.peek()
+	if !ok {
+		return dst, s.errUnexpected("looking for beginning of value")
+	}
+
+	switch {
+	case c == '{' || c == '[':
+		return s.composite(dst)
+	case c == '"':
+		return s.string(dst)
+	case c == '-' || isDigit(c):
+		return s.number(dst)
+	case c == 't':
+		return s.literal(dst, "true")
+	case c == 'f':
+		return s.literal(dst, "false")
+	case c == 'n':
+		return s.literal(dst, "null")
+	default:
+		return dst, s.errUnexpected("looking for beginning of value")
+	}
+}
+
+// composite appends the raw object or array to dst.
+func (s *scanner) composite(dst []byte) ([]byte, error) {
+	c, _ := s.next()
+	dst = append(dst, c)
+
+	closing := byte(']')
+	if c == '{' {
+		closing = '}'
+	}
+
+	dst = s.whitespace(dst)
+	if next, ok := s.peek(); ok && next == closing {
+		s.pos++
+		return append(dst, closing), nil
+	}
+
+	for {
+		var err error
+
+		if closing == '}' {
+			if dst, err = s.string(dst); err != nil {
+				return dst, err
+			}
+			dst = s.whitespace(dst)
+			if err = s.expect(':', "after object key"); err != nil {
+				return dst, err
+			}
+			dst = s.whitespace(append(dst, ':'))
+		}
+
+		if dst, err = s.value(dst); err != nil {
+			return dst, err
+		}
+		dst = s.whitespace(dst)
+
+		next, ok := s.next()
+		switch {
+		case ok && next == ',':
+			dst = s.whitespace(append(dst, ','))
+		case ok && next == closing:
+			return append(dst, closing), nil
+		default:
+			if ok {
+				s.pos--
+			}
+			return dst, s.errUnexpected("after value")
+		}
+	}
+}
+
+// string appends the raw string literal including quotes to dst.
+func (s *scanner) string(dst []byte) ([]byte, error) {
+	if err := s.expect('"', "looking for beginning of string"); err != nil {
+		return dst, err
+	}
+	dst = append(dst, '"')
+
+	for {
+		c, ok := s.next()
+		switch {
+		case !ok:
+			return dst, s.errUnexpected("in string literal")
+		case c == '"':
+			return append(dst, c), nil
+		case c < 0x20:
+			s.pos--
+			return dst, s.errUnexpected("in string literal")
+		case c == '\\':
+			dst = append(dst, c)
+			if c, ok = s.next(); !ok {
+				return dst, s.errUnexpected("in string escape code")
+			}
+			switch c {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				dst = append(dst, c)
+			case 'u':
+				dst = append(dst, c)
+				for i := 0; i < 4; i++ {
+					if c, ok = s.next(); !ok || !isHex(c) {
+						if ok {
+							s.pos--
+						}
+						return dst, s.errUnexpected("in \\u hexadecimal character escape")
+					}
+					dst = append(dst, c)
+				}
+			default:
+				s.pos--
+				return dst, s.errUnexpected("in string escape code")
+			}
+		default:
+			dst = append(dst, c)
+		}
+	}
+}
+
+// number appends the raw number literal to dst validating its grammar.
+func (s *scanner) number(dst []byte) ([]byte, error) {
+	c, _ := s.peek()
+	if c == '-' {
+		dst = append(dst, c)
+		s.pos++
+	}
+
+	// Integer part: either 0 or non-zero digit followed by digits.
+	if c, ok := s.peek(); !ok || !isDigit(c) {
+		return dst, s.errUnexpected("in numeric literal")
+	} else if c == '0' {
+		dst = append(dst, c)
+		s.pos++
+	} else {
+		dst = s.digits(dst)
+	}
+
+	if c, ok := s.peek(); ok && c == '.' {
+		dst = append(dst, c)
+		s.pos++
+		if c, ok = s.peek(); !ok || !isDigit(c) {
+			return dst, s.errUnexpected("after decimal point in numeric literal")
+		}
+		dst = s.digits(dst)
+	}
+
+	if c, ok := s.peek(); ok && (c == 'e' || c == 'E') {
+		dst = append(dst, c)
+		s.pos++
+		if c, ok = s.peek(); ok && (c == '+' || c == '-') {
+			dst = append(dst, c)
+			s.pos++
+		}
+		if c, ok = s.peek(); !ok || !isDigit(c) {
+			return dst, s.errUnexpected("in exponent of numeric literal")
+		}
+		dst = s.digits(dst)
+	}
+
+	return dst, nil
+}
+
+// digits appends the sequence of digits to dst.
+func (s *scanner) digits(dst []byte) []byte {
+	for {
+		c, ok := s.peek()
+		if !ok || !isDigit(c) {
+			return dst
+		}
+		dst = append(dst, c)
+		s.pos++
+	}
+}
+
+// literal appends the literal (true, false or null) to dst.
+func (s *scanner) literal(dst []byte, literal string) ([]byte, error) {
+	for i := 0; i < len(literal); i++ {
+		if err := s.expect(literal[i], "in literal "+literal); err != nil {
+			return dst, err
+		}
+	}
+
+	return append(dst, literal...), nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
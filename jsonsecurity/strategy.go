@@ -0,0 +1,42 @@
+package jsonsecurity
+
+// MaskStrategy parameterizes built-in labels and the ones registered with RegisterStrategyLabel. When it's specified in
+// TriggerOpts it replaces the defaults of the label completely, e.g. CARD_NUMBER keeps 6 leading and 4 trailing
+// characters by default, while &MaskStrategy{KeepTrailing: 4} keeps the last 4 ones only.
+type MaskStrategy struct {
+	// KeepLeading is a number of leading characters left unmasked.
+	KeepLeading int
+	// KeepTrailing is a number of trailing characters left unmasked.
+	KeepTrailing int
+	// MaskSymbol replaces masked characters. MaskSymbol constant is used if empty.
+	MaskSymbol string
+	// FixedLength replaces the masked part with exactly FixedLength symbols in order to hide the original length.
+	// The length is preserved if zero.
+	FixedLength int
+}
+
+// orDefault returns the strategy or def if the strategy is not specified.
+func (s *MaskStrategy) orDefault(def MaskStrategy) MaskStrategy {
+	if s == nil {
+		return def
+	}
+
+	ret := *s
+	if ret.KeepLeading < 0 {
+		ret.KeepLeading = 0
+	}
+	if ret.KeepTrailing < 0 {
+		ret.KeepTrailing = 0
+	}
+
+	return ret
+}
+
+// symbol returns the symbol masked characters are replaced with.
+func (s MaskStrategy) symbol() string {
+	if s.MaskSymbol == "" {
+		return MaskSymbol
+	}
+
+	return s.MaskSymbol
+}
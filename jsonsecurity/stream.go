@@ -0,0 +1,458 @@
+package jsonsecurity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// streamFlushSize is a size of the output buffered before it's written to io.Writer.
+const streamFlushSize = 32 * 1024
+
+// streamMasker masks json token by token. Values which are not masked are copied as is, so the order of keys, number
+// literals, escaping and whitespaces of the input are preserved.
+type streamMasker struct {
+	m   *Masker
+	src *scanner
+
+	out []byte
+	// w is the destination of out. The output is kept in out entirely when w is nil.
+	w io.Writer
+
+	// The buffers below are reused between calls.
+	//
+	// scratch captures values to be masked, key and colon store raw key and the colon with surrounding whitespaces of
+	// the current member, separators store separators of the objects and arrays being masked indexed by depth.
+	scratch    []byte
+	key        []byte
+	colon      []byte
+	separators []*separator
+}
+
+var _streamMaskers = sync.Pool{New: func() any { return new(streamMasker) }}
+
+// acquireStreamMasker returns streamMasker from the pool.
+func acquireStreamMasker(m *Masker, src *scanner, out []byte, w io.Writer) *streamMasker {
+	sm := _streamMaskers.Get().(*streamMasker)
+	sm.m, sm.src, sm.out, sm.w = m, src, out, w
+	return sm
+}
+
+// release returns streamMasker to the pool.
+func (sm *streamMasker) release() {
+	sm.m, sm.src, sm.out, sm.w = nil, nil, nil, nil
+	_streamMaskers.Put(sm)
+}
+
+// MaskBytes masks json data the same way Mask does.
+func (m *Masker) MaskBytes(data []byte) ([]byte, error) {
+	return m.maskDocument(data, nil, 0)
+}
+
+// MaskStream reads json document from r, masks it the same way Mask does and writes the result to w. The document is
+// not loaded into memory entirely. The output written before an error occurred is incomplete and must be discarded.
+func (m *Masker) MaskStream(w io.Writer, r io.Reader) error {
+	sm := acquireStreamMasker(m, newReaderScanner(r), make([]byte, 0, streamFlushSize), w)
+	defer sm.release()
+
+	if err := sm.document(nil, 0); err != nil {
+		return err
+	}
+
+	return sm.flush(true)
+}
+
+// separator returns reset separator for the object or array located at depth.
+func (sm *streamMasker) separator(depth int) *separator {
+	for len(sm.separators) <= depth {
+		sm.separators = append(sm.separators, new(separator))
+	}
+
+	sep := sm.separators[depth]
+	sep.written = false
+	return sep
+}
+
+// maskDocument masks json document located at path, e.g. embedded into string value.
+func (m *Masker) maskDocument(data []byte, path []pathElem, depth int) ([]byte, error) {
+	sm := acquireStreamMasker(m, newBytesScanner(data), make([]byte, 0, len(data)), nil)
+	defer sm.release()
+
+	if err := sm.document(path, depth); err != nil {
+		return nil, err
+	}
+
+	return sm.out, nil
+}
+
+// document masks the top-level value located at path.
+func (sm *streamMasker) document(path []pathElem, depth int) error {
+	sm.out = sm.src.whitespace(sm.out)
+	if err := sm.value(path, depth); err != nil {
+		return err
+	}
+	sm.out = sm.src.whitespace(sm.out)
+
+	return sm.src.eof()
+}
+
+// flush writes the output to w when it's large enough or when force is set.
+func (sm *streamMasker) flush(force bool) error {
+	if sm.w == nil || len(sm.out) == 0 || !force && len(sm.out) < streamFlushSize {
+		return nil
+	}
+
+	if _, err := sm.w.Write(sm.out); err != nil {
+		return fmt.Errorf("failed to write output: %+v", err)
+	}
+	sm.out = sm.out[:0]
+
+	return nil
+}
+
+// value masks the value located at path.
+func (sm *streamMasker) value(path []pathElem, depth int) error {
+	if depth > sm.m.cfg.MaxDepth {
+		return fmt.Errorf("max recursion depth reached: %d", sm.m.cfg.MaxDepth)
+	}
+
+	c, ok := sm.src.peek()
+	if !ok {
+		return sm.src.errUnexpected("looking for beginning of value")
+	}
+
+	switch c {
+	case '{':
+		return sm.object(path, depth)
+	case '[':
+		return sm.array(path, depth)
+	default:
+		return sm.leaf(path, depth)
+	}
+}
+
+// object masks the object located at path. Members removed by triggers are omitted along with their separators.
+func (sm *streamMasker) object(path []pathElem, depth int) error {
+	sm.src.pos++
+	sm.out = append(sm.out, '{')
+
+	sep := sm.separator(depth)
+	sep.first = sm.src.whitespace(sep.first[:0])
+	if c, ok := sm.src.peek(); ok && c == '}' {
+		sm.src.pos++
+		sm.out = append(append(sm.out, sep.first...), '}')
+		return nil
+	}
+
+	for {
+		var err error
+
+		// Raw key and colon are written before nested values are masked, so the buffers may be reused by them.
+		if sm.key, err = sm.src.string(sm.key[:0]); err != nil {
+			return err
+		}
+		rawKey := sm.key
+		key, err := decodeString(rawKey)
+		if err != nil {
+			return err
+		}
+
+		sm.colon = sm.src.whitespace(sm.colon[:0])
+		if err = sm.src.expect(':', "after object key"); err != nil {
+			return err
+		}
+		sm.colon = sm.src.whitespace(append(sm.colon, ':'))
+		colon := sm.colon
+
+		// The path is not retained by callees, so its backing array is reused by siblings.
+		childPath := append(path, pathElem{key: key})
+
+		appear := true
+		if opts, ok := sm.m.findTrigger(childPath); ok {
+			appear, err = sm.maskTrigger(opts, key, rawKey, colon, sep)
+			if err != nil {
+				return fmt.Errorf("fail while masking key '%s', depth '%d': %+v", key, depth, err)
+			}
+		} else {
+			sm.out = sep.write(sm.out)
+			sm.out = append(append(sm.out, rawKey...), colon...)
+			if err = sm.value(childPath, depth+1); err != nil {
+				return fmt.Errorf("fail while walking through key '%s', depth '%d': %+v", key, depth, err)
+			}
+		}
+
+		sep.ws = sm.src.whitespace(sep.ws[:0])
+		if err = sm.flush(false); err != nil {
+			return err
+		}
+
+		c, ok := sm.src.next()
+		switch {
+		case ok && c == ',':
+			sep.next(appear)
+			sep.lead = sm.src.whitespace(sep.lead[:0])
+		case ok && c == '}':
+			sm.out = append(append(sm.out, sep.ws...), '}')
+			return nil
+		default:
+			if ok {
+				sm.src.pos--
+			}
+			return sm.src.errUnexpected("after object key:value pair")
+		}
+	}
+}
+
+// array masks the array located at path. Elements removed by path triggers are omitted along with their separators.
+func (sm *streamMasker) array(path []pathElem, depth int) error {
+	sm.src.pos++
+	sm.out = append(sm.out, '[')
+
+	sep := sm.separator(depth)
+	sep.first = sm.src.whitespace(sep.first[:0])
+	if c, ok := sm.src.peek(); ok && c == ']' {
+		sm.src.pos++
+		sm.out = append(append(sm.out, sep.first...), ']')
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		var err error
+
+		childPath := append(path, pathElem{index: i, isIndex: true})
+
+		appear := true
+		// Only path triggers may refer to array elements.
+		if opts, ok := sm.m.findPathTrigger(childPath); ok {
+			appear, err = sm.maskTrigger(opts, strconv.Itoa(i), nil, nil, sep)
+		} else {
+			sm.out = sep.write(sm.out)
+			err = sm.value(childPath, depth+1)
+		}
+		if err != nil {
+			return fmt.Errorf("fail while masking array index '%d', depth '%d': %+v", i, depth, err)
+		}
+
+		sep.ws = sm.src.whitespace(sep.ws[:0])
+		if err = sm.flush(false); err != nil {
+			return err
+		}
+
+		c, ok := sm.src.next()
+		switch {
+		case ok && c == ',':
+			sep.next(appear)
+			sep.lead = sm.src.whitespace(sep.lead[:0])
+		case ok && c == ']':
+			sm.out = append(append(sm.out, sep.ws...), ']')
+			return nil
+		default:
+			if ok {
+				sm.src.pos--
+			}
+			return sm.src.errUnexpected("after array element")
+		}
+	}
+}
+
+// maskTrigger masks the value using trigger options and writes it preceded by separator, raw key and colon (the latter
+// are nil for array elements). It reports whether the value appears in the output.
+func (sm *streamMasker) maskTrigger(
+	opts TriggerOpts,
+	key string,
+	rawKey, colon []byte,
+	sep *separator,
+) (bool, error) {
+	var err error
+
+	sm.scratch, err = sm.src.value(sm.scratch[:0])
+	if err != nil {
+		return false, err
+	}
+
+	value, err := decodeValue(sm.scratch)
+	if err != nil {
+		return false, err
+	}
+
+	ret, ok, err := sm.m.MaskTrigger(opts, key, value)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	masked, err := json.Marshal(ret.Value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal masked value: %+v", err)
+	}
+
+	sm.out = sep.write(sm.out)
+	if rawKey != nil {
+		if ret.Key != key {
+			if rawKey, err = json.Marshal(ret.Key); err != nil {
+				return false, fmt.Errorf("failed to marshal masked key: %+v", err)
+			}
+		}
+		sm.out = append(append(sm.out, rawKey...), colon...)
+	}
+	sm.out = append(sm.out, masked...)
+
+	return true, nil
+}
+
+// leaf copies the scalar value located at path masking it if it's recognized by detectors, contains embedded json
+// document or isn't allowlisted in default-deny mode.
+func (sm *streamMasker) leaf(path []pathElem, depth int) error {
+	start := len(sm.out)
+
+	var err error
+	if sm.out, err = sm.src.value(sm.out); err != nil {
+		return err
+	}
+
+	embedded := sm.m.cfg.Embedded.JSON || sm.m.cfg.Embedded.Base64
+	deny := sm.m.allowlist != nil
+	if !deny && (len(path) == 0 || len(sm.m.detectors) == 0 && !embedded) {
+		return nil
+	}
+
+	raw := sm.out[start:]
+
+	var value interface{}
+	switch raw[0] {
+	case '"':
+		s, err := decodeString(raw)
+		if err != nil {
+			return err
+		}
+
+		if embedded && len(path) != 0 {
+			masked, ok, err := sm.embedded(s, path, depth)
+			if err != nil {
+				return err
+			}
+			if ok {
+				sm.out = append(sm.out[:start], masked...)
+				return nil
+			}
+		}
+		value = s
+	case 'n':
+		return nil
+	case 't', 'f':
+		if !deny {
+			return nil
+		}
+		value = raw[0] == 't'
+	default:
+		value = json.Number(raw)
+	}
+
+	denied, ok, err := sm.m.maskDenied(path, value)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return sm.replace(start, denied)
+	}
+
+	if len(sm.m.detectors) == 0 || len(path) == 0 {
+		return nil
+	}
+
+	label, ok := sm.m.detect(path, fmt.Sprint(value))
+	if !ok {
+		return nil
+	}
+
+	ret, err := sm.m.MaskWith(label, path[len(path)-1].key, value)
+	if err != nil {
+		return err
+	}
+
+	return sm.replace(start, ret.Value)
+}
+
+// replace replaces the output starting at start with json of the masked value.
+func (sm *streamMasker) replace(start int, value interface{}) error {
+	masked, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal masked value: %+v", err)
+	}
+	sm.out = append(sm.out[:start], masked...)
+
+	return nil
+}
+
+// separator writes commas and whitespaces between members of object or elements of array. Whitespaces of removed
+// members are omitted.
+type separator struct {
+	// first is the whitespace preceding the first member. It's kept regardless of the member being removed.
+	first []byte
+	// lead is the whitespace following the last comma.
+	lead []byte
+	// trailing is the whitespace following the last written member.
+	trailing []byte
+	// ws is the whitespace following the current member.
+	ws      []byte
+	written bool
+}
+
+// write appends the separator preceding the member to dst.
+func (s *separator) write(dst []byte) []byte {
+	if !s.written {
+		return append(dst, s.first...)
+	}
+	return append(append(append(dst, s.trailing...), ','), s.lead...)
+}
+
+// next moves to the next member. The whitespace following the current member is kept if the member appears in the
+// output.
+func (s *separator) next(appear bool) {
+	if appear {
+		s.trailing, s.ws, s.written = s.ws, s.trailing, true
+	}
+}
+
+// decodeString decodes raw json string literal.
+func decodeString(raw []byte) (string, error) {
+	if bytes.IndexByte(raw, '\\') == -1 {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", fmt.Errorf("failed to decode string: %+v", err)
+	}
+
+	return s, nil
+}
+
+// decodeValue decodes raw json value keeping numbers as json.Number. The value must be valid.
+func decodeValue(raw []byte) (interface{}, error) {
+	switch raw[0] {
+	case '"':
+		return decodeString(raw)
+	case 't':
+		return true, nil
+	case 'f':
+		return false, nil
+	case 'n':
+		return nil, nil
+	case '{', '[':
+	default:
+		return json.Number(raw), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %+v", err)
+	}
+
+	return value, nil
+}
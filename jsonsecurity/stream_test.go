@@ -0,0 +1,251 @@
+package jsonsecurity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// withStreamConfig allows deep documents and removes the second item of the list.
+func withStreamConfig(cfg *Config) {
+	cfg.MaxDepth = 10
+	cfg.PathTriggers = map[string]TriggerOpts{"list[1]": {ShouldAppear: false}}
+}
+
+func TestMasker_MaskBytes_ShouldPreserveLayout(t *testing.T) {
+	masker := newTestMasker(withStreamConfig)
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: `{}`, want: `{}`},
+		{data: ` [ ] `, want: ` [ ] `},
+		{data: `"password"`, want: `"password"`},
+		{
+			data: `{"z": 1, "account_id": 12345678901234567890, "a": 1.50e+10, "password": "qwerty"}`,
+			want: `{"z": 1, "account_id": 12345678901234567890, "a": 1.50e+10, "password": "******"}`,
+		},
+		{
+			data: "{\n  \"name\": \"\\u0041nn\",\n  \"card\": 4111111111111111,\n  \"nested\": {\"password\": [1, 2]}\n}",
+			want: "{\n  \"name\": \"\\u0041nn\",\n  \"card\": \"411111******1111\",\n  \"nested\": {\"password\": \"*****\"}\n}",
+		},
+		{data: `{"secret": 1}`, want: `{}`},
+		{data: `{ "secret": 1 }`, want: `{ }`},
+		{data: `{"secret": 1, "a": 1}`, want: `{"a": 1}`},
+		{data: `{"a": 1, "secret": 1, "b": 2}`, want: `{"a": 1, "b": 2}`},
+		{data: "{\n  \"a\": 1,\n  \"secret\": 1\n}", want: "{\n  \"a\": 1\n}"},
+		{data: `{"list": [1, 2, 3]}`, want: `{"list": [1, 3]}`},
+		{data: `{"list": [1, 2]}`, want: `{"list": [1]}`},
+		{data: `[{"password": "x"}, true, null, "a\"b"]`, want: `[{"password": "*"}, true, null, "a\"b"]`},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.MaskBytes([]byte(tt.data))
+		assert.NoError(t, err, tt.data)
+		assert.Equal(t, tt.want, string(ret), tt.data)
+	}
+}
+
+func TestMasker_MaskBytes_ShouldFail_WhenJSONIsInvalid(t *testing.T) {
+	masker := newTestMasker(withStreamConfig)
+
+	invalid := []string{
+		``, ` `, `{`, `}`, `{"a"}`, `{"a":}`, `{"a":1,}`, `{"a":1 "b":2}`, `[1,]`, `[1 2]`, `01`, `1.`, `-`, `1e`,
+		`tru`, `nul`, `"abc`, "\"a\tb\"", `"\x"`, `"\u12"`, `{} {}`, `{a:1}`, `{"password": [1,}`, `[1]]`,
+	}
+
+	for _, data := range invalid {
+		assert.False(t, json.Valid([]byte(data)), data)
+
+		_, err := masker.MaskBytes([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestMasker_MaskStream(t *testing.T) {
+	masker := newTestMasker(withStreamConfig)
+
+	data := `{"items": [` + strings.Repeat(`{"id": 123456789012345678, "password": "qwerty", "secret": "s"}, `, 2000) +
+		`{}]}`
+	want := `{"items": [` + strings.Repeat(`{"id": 123456789012345678, "password": "******"}, `, 2000) + `{}]}`
+
+	out := new(bytes.Buffer)
+	err := masker.MaskStream(out, iotest.HalfReader(strings.NewReader(data)))
+	assert.NoError(t, err)
+	assert.Equal(t, want, out.String())
+
+	out.Reset()
+	err = masker.MaskStream(out, iotest.OneByteReader(strings.NewReader(`{"password": "\u0041bc"}`)))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password": "***"}`, out.String())
+
+	err = masker.MaskStream(out, iotest.ErrReader(fmt.Errorf("broken")))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "broken")
+	}
+}
+
+func TestMasker_MaskValue_ShouldKeepNumberPrecision(t *testing.T) {
+	masker := newTestMasker(withStreamConfig)
+
+	ret, err := masker.MaskValue(map[string]any{"id": uint64(12345678901234567890), "password": "qwerty"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": json.Number("12345678901234567890"), "password": "******"}, ret)
+}
+
+func TestMasker_MaskBytes_ShouldMatchReferenceImplementation(t *testing.T) {
+	masker := newTestMasker(withStreamConfig)
+	data := benchmarkDocument()
+
+	ret, err := masker.MaskBytes(data)
+	assert.NoError(t, err)
+
+	want, err := referenceMask(masker, data)
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, string(want), string(ret))
+}
+
+func BenchmarkMasker_Mask(b *testing.B) {
+	masker := newTestMasker(withStreamConfig)
+	data := benchmarkDocument()
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := masker.MaskBytes(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("reference", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := referenceMask(masker, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// benchmarkDocument returns a typical payload logged with field.JSONEscapeSecure.
+func benchmarkDocument() []byte {
+	items := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		items = append(items, fmt.Sprintf(
+			`{"id": %d, "title": "item %d", "price": 1234.56, "card": "4111111111111111", "tags": ["a", "b", "c"]}`,
+			9007199254740993+i, i,
+		))
+	}
+
+	return []byte(`{
+  "request_id": "0b9f6c1e-7a43-4e0c-8f64-2a4f2c1d9e10",
+  "user": {"login": "john", "password": "qwerty123", "secret": "s3cr3t", "email": "john@example.com"},
+  "items": [` + strings.Join(items, ", ") + `],
+  "list": [1, 2, 3],
+  "total": 24691.2
+}`)
+}
+
+// referenceMask is the former implementation of Masker.Mask kept to compare results and performance. It unmarshals the
+// data into map, masks it and marshals the result back.
+func referenceMask(m *Masker, data []byte) ([]byte, error) {
+	var dataAny any
+	if err := json.Unmarshal(data, &dataAny); err != nil {
+		return nil, err
+	}
+
+	result, err := referenceWalkthrough(m, dataAny, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(result)
+}
+
+func referenceWalkthrough(m *Masker, layer interface{}, path []pathElem, depth int) (interface{}, error) {
+	if depth > m.cfg.MaxDepth {
+		return nil, fmt.Errorf("max recursion depth reached: %d", m.cfg.MaxDepth)
+	}
+
+	switch t := layer.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			var err error
+
+			childPath := appendPath(path, pathElem{key: k})
+			opts, ok := m.findTrigger(childPath)
+			if !ok {
+				if t[k], err = referenceWalkthrough(m, v, childPath, depth+1); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			ret, appear, err := m.MaskTrigger(opts, k, v)
+			if err != nil {
+				return nil, err
+			}
+			delete(t, k)
+			if appear {
+				t[ret.Key] = ret.Value
+			}
+		}
+	case []interface{}:
+		ret := t[:0]
+		for i, v := range t {
+			childPath := appendPath(path, pathElem{index: i, isIndex: true})
+			if opts, ok := m.findPathTrigger(childPath); ok {
+				masked, appear, err := m.MaskTrigger(opts, fmt.Sprint(i), v)
+				if err != nil {
+					return nil, err
+				}
+				if appear {
+					ret = append(ret, masked.Value)
+				}
+				continue
+			}
+
+			masked, err := referenceWalkthrough(m, v, childPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, masked)
+		}
+		return ret, nil
+	}
+
+	return layer, nil
+}
+
+type testStringer struct{ value string }
+
+func (s testStringer) String() string { return "stringer " + s.value }
+
+func TestMasker_MaskValue_ShouldUseText_WhenValueIsErrorOrStringer(t *testing.T) {
+	masker := newTestMasker(withStreamConfig)
+
+	tests := []struct {
+		value any
+		want  any
+	}{
+		{value: fmt.Errorf("connection refused"), want: "connection refused"},
+		{value: testStringer{value: "abc"}, want: "stringer abc"},
+		{value: json.Number("12"), want: json.Number("12")},
+		{value: map[string]any{"err": "x", "password": "ab"}, want: map[string]any{"err": "x", "password": "**"}},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.MaskValue(tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, ret, tt.value)
+	}
+}
@@ -0,0 +1,70 @@
+package jsonsecurity
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200d'
+	// replacementChar replaces invalid UTF-8 sequences.
+	replacementChar = "\ufffd"
+)
+
+// graphemes splits the value into user-perceived characters, so combining marks, variation selectors, emoji modifiers,
+// ZWJ sequences and regional indicator pairs (flags) are never separated from their base character. Invalid UTF-8
+// sequences are replaced with U+FFFD.
+func graphemes(value string) []string {
+	value = strings.ToValidUTF8(value, replacementChar)
+
+	ret := make([]string, 0, utf8.RuneCountInString(value))
+	for start := 0; start < len(value); {
+		r, size := utf8.DecodeRuneInString(value[start:])
+		end := start + size
+
+		regional := isRegionalIndicator(r)
+		for end < len(value) {
+			next, nextSize := utf8.DecodeRuneInString(value[end:])
+			switch {
+			case isExtending(next):
+				end += nextSize
+				continue
+			case next == zeroWidthJoiner:
+				end += nextSize
+				if end < len(value) {
+					_, joinedSize := utf8.DecodeRuneInString(value[end:])
+					end += joinedSize
+				}
+				continue
+			case regional && isRegionalIndicator(next):
+				end += nextSize
+				regional = false
+				continue
+			}
+			break
+		}
+
+		ret = append(ret, value[start:end])
+		start = end
+	}
+
+	return ret
+}
+
+// isExtending reports whether the rune extends the preceding character.
+func isExtending(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r >= 0xfe00 && r <= 0xfe0f || // variation selectors
+		r >= 0x1f3fb && r <= 0x1f3ff // emoji skin tone modifiers
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isSpaceGrapheme reports whether the character is a whitespace.
+func isSpaceGrapheme(g string) bool {
+	r, _ := utf8.DecodeRuneInString(g)
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,76 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "", want: []string{}},
+		{value: "abc", want: []string{"a", "b", "c"}},
+		{value: "Иван", want: []string{"И", "в", "а", "н"}},
+		{value: "été", want: []string{"é", "t", "é"}},
+		{value: "👍🏽!", want: []string{"👍🏽", "!"}},
+		{value: "👩‍💻x", want: []string{"👩‍💻", "x"}},
+		{value: "🇷🇺🇩🇪", want: []string{"🇷🇺", "🇩🇪"}},
+		{value: "a\xffb", want: []string{"a", "�", "b"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, graphemes(tt.value), tt.value)
+	}
+}
+
+func TestLabels_ShouldMaskUnicode(t *testing.T) {
+	tests := []struct {
+		label MaskerLabel
+		value string
+		want  string
+	}{
+		// Latin
+		{label: MaskerLabelName, value: "John Smith", want: "J*** ****h"},
+		{label: MaskerLabelPassword, value: "pässwörd", want: "********"},
+		// Cyrillic
+		{label: MaskerLabelName, value: "Иван Петров", want: "И*** *****в"},
+		{label: MaskerLabelPassword, value: "пароль", want: "******"},
+		{label: MaskerLabelEmail, value: "иван@пример.рф", want: "и***@пример.рф"},
+		{label: MaskerLabelEmail, value: "ivan@xn--e1afmkfd.xn--p1ai", want: "i***@xn--e1afmkfd.xn--p1ai"},
+		// Greek
+		{label: MaskerLabelName, value: "Νίκος", want: "Ν***ς"},
+		// CJK
+		{label: MaskerLabelName, value: "山田太郎", want: "山**郎"},
+		{label: MaskerLabelEmail, value: "用户@例子.广告", want: "用*@例子.广告"},
+		// Arabic
+		{label: MaskerLabelName, value: "محمد", want: "م**د"},
+		// Mixed scripts, combining marks and emoji
+		{label: MaskerLabelName, value: "Анна-Maria", want: "А********a"},
+		{label: MaskerLabelName, value: "Amélie", want: "A****e"},
+		{label: MaskerLabelName, value: "👩‍💻Ann👍🏽", want: "👩‍💻***👍🏽"},
+		{label: MaskerLabelCVV, value: "１２３", want: "***"},
+		{label: MaskerLabelPhoneNumber, value: "+7 916 ١٢٣ 45 67", want: "+7 *** *** *5 67"},
+		// Invalid UTF-8
+		{label: MaskerLabelName, value: "\xffab\xfe", want: "�**�"},
+	}
+
+	masker, err := NewMasker(Config{})
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		ret, err := masker.MaskWith(tt.label, "key", tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, ret.Value, tt.value)
+		assert.True(t, utf8.ValidString(ret.Value.(string)), tt.value)
+	}
+}
+
+func TestDetector_ShouldDetectInternationalizedEmail(t *testing.T) {
+	masker, err := NewMasker(Config{Redaction: RedactionConfig{Enabled: true, Detectors: []Detector{DetectorEmail}}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "письмо от и***@пример.рф", masker.Redact("письмо от иван@пример.рф"))
+}
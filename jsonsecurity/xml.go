@@ -0,0 +1,236 @@
+package jsonsecurity
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// _xmlAttrRegex matches attributes of the raw start tag. Groups: 1 - name, 2 - quoted value.
+var _xmlAttrRegex = regexp.MustCompile(`\s+([^\s=/>]+)\s*=\s*("[^"]*"|'[^']*')`)
+
+// MaskXML masks xml document using the triggers. Element and attribute names without namespace prefix are used as keys,
+// path triggers see the names of enclosing elements, e.g. `Envelope.Body.card`. The text of the triggered element
+// (including the text of its descendants) is masked, the element is removed if the trigger doesn't allow it to appear.
+// Markup, comments and whitespace of the document are kept as is.
+func (m *Masker) MaskXML(data string) (string, error) {
+	x := &xmlMasker{m: m, data: data, dec: xml.NewDecoder(strings.NewReader(data))}
+	x.out.Grow(len(data))
+
+	return x.mask()
+}
+
+// xmlMasker copies raw tokens of the document replacing the masked values.
+type xmlMasker struct {
+	m    *Masker
+	data string
+	dec  *xml.Decoder
+	out  strings.Builder
+	// last is the offset of the end of the previous token.
+	last int64
+	// path and triggers describe open elements. Trigger of the element is inherited by its descendants.
+	path     []pathElem
+	triggers []*xmlTrigger
+}
+
+// xmlTrigger is the trigger of the element along with the element name used as the key.
+type xmlTrigger struct {
+	opts TriggerOpts
+	key  string
+}
+
+// mask walks through the tokens of the document.
+func (x *xmlMasker) mask() (string, error) {
+	root := false
+
+	for {
+		tok, err := x.dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid xml: %+v", err)
+		}
+
+		raw := x.raw()
+		switch t := tok.(type) {
+		case xml.StartElement:
+			root = true
+			err = x.start(t, raw)
+		case xml.EndElement:
+			x.out.WriteString(raw)
+			x.path = x.path[:len(x.path)-1]
+			x.triggers = x.triggers[:len(x.triggers)-1]
+		case xml.CharData:
+			err = x.text(string(t), raw)
+		default:
+			x.out.WriteString(raw)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !root {
+		return "", fmt.Errorf("invalid xml: no root element")
+	}
+
+	return x.out.String(), nil
+}
+
+// raw returns raw text of the token just read.
+func (x *xmlMasker) raw() string {
+	offset := x.dec.InputOffset()
+	raw := x.data[x.last:offset]
+	x.last = offset
+
+	return raw
+}
+
+// inherited returns the trigger of the closest triggered ancestor if any.
+func (x *xmlMasker) inherited() *xmlTrigger {
+	if len(x.triggers) == 0 {
+		return nil
+	}
+	return x.triggers[len(x.triggers)-1]
+}
+
+// start writes start tag with masked attributes. The element is skipped if the trigger doesn't allow it to appear.
+func (x *xmlMasker) start(el xml.StartElement, raw string) error {
+	path := appendPath(x.path, pathElem{key: el.Name.Local})
+	if len(path) > x.m.cfg.MaxDepth {
+		return fmt.Errorf("max recursion depth reached: %d", x.m.cfg.MaxDepth)
+	}
+
+	trigger := x.inherited()
+	if trigger == nil {
+		if opts, ok := x.m.findTrigger(path); ok {
+			trigger = &xmlTrigger{opts: opts, key: el.Name.Local}
+		}
+	}
+
+	if trigger != nil && !trigger.opts.ShouldAppear && trigger.opts.Replacement == "" {
+		if err := x.dec.Skip(); err != nil {
+			return fmt.Errorf("invalid xml: %+v", err)
+		}
+		x.last = x.dec.InputOffset()
+		return nil
+	}
+
+	tag, err := x.attrs(el, raw, path)
+	if err != nil {
+		return fmt.Errorf("fail while masking attributes of element '%s': %+v", el.Name.Local, err)
+	}
+	x.out.WriteString(tag)
+
+	x.path = path
+	x.triggers = append(x.triggers, trigger)
+
+	return nil
+}
+
+// attrs returns raw start tag with the values of attributes masked. Attributes of the elements located
+// inside the triggered one are masked with its trigger. Namespace declarations are never masked.
+func (x *xmlMasker) attrs(el xml.StartElement, raw string, path []pathElem) (string, error) {
+	matches := _xmlAttrRegex.FindAllStringSubmatchIndex(raw, -1)
+	if len(matches) != len(el.Attr) {
+		// Raw tag is never reported, since it contains the values of attributes.
+		return "", fmt.Errorf("unexpected attributes in element '%s'", el.Name.Local)
+	}
+
+	var (
+		b    strings.Builder
+		last int
+	)
+
+	for i, attr := range el.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+
+		attrPath := appendPath(path, pathElem{key: attr.Name.Local})
+		trigger := x.inherited()
+		if trigger == nil {
+			if opts, ok := x.m.findTrigger(attrPath); ok {
+				trigger = &xmlTrigger{opts: opts, key: attr.Name.Local}
+			}
+		}
+
+		masked, changed, ok, err := x.maskValue(trigger, attrPath, attr.Value)
+		if err != nil {
+			return "", err
+		}
+		if !changed {
+			continue
+		}
+
+		idx := matches[i]
+		if !ok {
+			b.WriteString(raw[last:idx[0]])
+			last = idx[1]
+			continue
+		}
+
+		quote := raw[idx[4] : idx[4]+1]
+		b.WriteString(raw[last:idx[4]])
+		b.WriteString(quote + masked + quote)
+		last = idx[5]
+	}
+	b.WriteString(raw[last:])
+
+	return b.String(), nil
+}
+
+// text writes the text masked with inherited trigger or in default-deny mode. Whitespace between elements stays as is.
+func (x *xmlMasker) text(text string, raw string) error {
+	if strings.TrimSpace(text) == "" {
+		x.out.WriteString(raw)
+		return nil
+	}
+
+	masked, changed, ok, err := x.maskValue(x.inherited(), x.path, text)
+	switch {
+	case err != nil:
+		return fmt.Errorf("fail while masking text: %+v", err)
+	case !changed:
+		x.out.WriteString(raw)
+	case ok:
+		x.out.WriteString(masked)
+	}
+
+	return nil
+}
+
+// maskValue masks the value of element or attribute located at path with the trigger. The value which is not
+// triggered is masked in default-deny mode unless it's allowlisted. The masked value is escaped. changed is false when
+// the value stays as is, ok is false when it must be removed.
+func (x *xmlMasker) maskValue(
+	trigger *xmlTrigger,
+	path []pathElem,
+	value string,
+) (masked string, changed, ok bool, err error) {
+	if trigger == nil {
+		denied, deny, err := x.m.maskDenied(path, value)
+		if err != nil || !deny {
+			return "", false, true, err
+		}
+		return escapeXML(fmt.Sprint(denied)), true, true, nil
+	}
+
+	ret, ok, err := x.m.MaskTrigger(trigger.opts, trigger.key, value)
+	if err != nil || !ok {
+		return "", true, false, err
+	}
+
+	return escapeXML(fmt.Sprint(ret.Value)), true, true, nil
+}
+
+// escapeXML escapes the text to be used either as character data or as attribute value.
+func escapeXML(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
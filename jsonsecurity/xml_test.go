@@ -0,0 +1,91 @@
+package jsonsecurity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// withMarkupConfig adds triggers of tokens and authorization headers and the path of SOAP card verification value.
+func withMarkupConfig(cfg *Config) {
+	cfg.Triggers["token"] = TriggerOpts{ShouldAppear: false, Replacement: "[HIDDEN]"}
+	cfg.Triggers["authorization"] = TriggerOpts{ShouldAppear: true, MaskMethod: MaskerLabelPassword}
+	cfg.PathTriggers = map[string]TriggerOpts{
+		"Envelope.Body.Pay.cvv": {ShouldAppear: true, MaskMethod: MaskerLabelPassword},
+	}
+}
+
+func TestMasker_MaskXML(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{
+			data: `<login><user>john</user><password>qwerty</password></login>`,
+			want: `<login><user>john</user><password>******</password></login>`,
+		},
+		{
+			data: `<?xml version="1.0"?>` + "\n<!-- c -->\n<a>\n  <password><![CDATA[a<b]]></password>\n  <b/>\n</a>",
+			want: `<?xml version="1.0"?>` + "\n<!-- c -->\n<a>\n  <password>***</password>\n  <b/>\n</a>",
+		},
+		{
+			data: `<a><password>a&amp;b</password></a>`,
+			want: `<a><password>***</password></a>`,
+		},
+		{
+			data: `<a><secret>1</secret><b>2</b><secret/></a>`,
+			want: `<a><b>2</b></a>`,
+		},
+		{
+			data: `<a><token>abc</token></a>`,
+			want: `<a><token>[HIDDEN]</token></a>`,
+		},
+		{
+			data: `<a><password><x>ab</x><y id="1">abc</y></password></a>`,
+			want: `<a><password><x>**</x><y id="*">***</y></password></a>`,
+		},
+		{
+			data: `<user id="1" password='qwerty' secret="s" name="john"/>`,
+			want: `<user id="1" password='******' name="john"/>`,
+		},
+		{
+			data: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><Pay>` +
+				`<card>4111111111111111</card><cvv>123</cvv></Pay><cvv>123</cvv></s:Body></s:Envelope>`,
+			want: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><Pay>` +
+				`<card>411111******1111</card><cvv>***</cvv></Pay><cvv>123</cvv></s:Body></s:Envelope>`,
+		},
+		{
+			data: `<a xmlns:password="urn:x"><password:b>1</password:b></a>`,
+			want: `<a xmlns:password="urn:x"><password:b>1</password:b></a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		ret, err := masker.MaskXML(tt.data)
+		assert.NoError(t, err, tt.data)
+		assert.Equal(t, tt.want, ret, tt.data)
+	}
+}
+
+func TestMasker_MaskXML_ShouldFail_WhenXMLIsInvalid(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	invalid := []string{``, `text`, `<a>`, `<a></b>`, `<a><b></a>`, `<a x=1></a>`, `<a>&bad;</a>`}
+	for _, data := range invalid {
+		_, err := masker.MaskXML(data)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestMasker_MaskXML_ShouldFail_WhenMaxDepthIsExceeded(t *testing.T) {
+	masker := newTestMasker(withMarkupConfig)
+
+	_, err := masker.MaskXML(`<a><b><c><d><e>1</e></d></c></b></a>`)
+	assert.NoError(t, err)
+
+	_, err = masker.MaskXML(`<a><b><c><d><e><f>1</f></e></d></c></b></a>`)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "max recursion depth")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Alp4ka/mlogger/jsonsecurity"
 	"github.com/Alp4ka/mlogger/misc"
 	"github.com/Alp4ka/mlogger/templates"
+	"github.com/Alp4ka/mlogger/tracecontext"
 	"io"
 	"log/slog"
 	"os"
@@ -35,53 +36,109 @@ type MainLogger struct {
 	logger *slog.Logger
 }
 
-func (l *MainLogger) log(level misc.Level, msg string, fields ...field.Field) {
+func (l *MainLogger) log(level misc.Level, event *Event, msg string, fields ...field.Field) {
 	if level.LessThan(l.cfg.Level) {
 		return
 	}
 
+	// Fields of the call are copied along with the fields of the context, so the slice of the caller stays untouched.
+	ctxFields := field.FieldsFromContext(l.ctx)
+	all := make(field.Fields, 0, len(ctxFields)+len(fields)+4)
+	all = append(append(all, ctxFields...), fields...)
+
+	if event != nil {
+		all = append(all, event.eventFields(all)...)
+	}
+	if tc, ok := tracecontext.FromContext(l.ctx); ok {
+		all = append(
+			all,
+			field.String(field.KeyTraceID, tc.TraceID.String()),
+			field.String(field.KeySpanID, tc.SpanID.String()),
+		)
+	}
+
 	// log is located
-	flds := field.FieldsFromContext(field.WithContextFields(l.ctx, fields...)).
+	flds := all.
 		WithOptions(field.OptionCallerFunc(callerFuncLevelShift)).
 		Prepare(l.masker)
 
-	attrs := field.UnpackFieldsToSlogAttrs(flds)
+	localFlds, alertFlds := flds.Local(), flds.Alert()
+	msg = l.masker.Redact(msg)
+	localMsg, alertMsg := msg, msg
+	if event != nil {
+		localMsg, alertMsg = event.render(localFlds), event.render(alertFlds)
+	}
 
-	go func() {
-		if err := l.gw.Msg(l.ctx, l.cfg.Source, misc.LevelDebug, msg, flds...); err != nil {
-			l.logger.LogAttrs(l.ctx, misc.LevelWarn, err.Error(), attrs...)
-		}
-	}()
+	attrs := field.UnpackFieldsToSlogAttrs(localFlds)
 
-	l.logger.LogAttrs(l.ctx, misc.SlogLevel(level), msg, attrs...)
+	if event == nil || event.Alert != AlertPolicyNever {
+		go func() {
+			if err := l.gw.Msg(l.ctx, l.cfg.Source, misc.LevelDebug, alertMsg, alertFlds...); err != nil {
+				l.logger.LogAttrs(l.ctx, misc.LevelWarn, err.Error(), attrs...)
+			}
+		}()
+	}
+
+	l.logger.LogAttrs(l.ctx, misc.SlogLevel(level), localMsg, attrs...)
 }
 
 func (l *MainLogger) Log(level misc.Level, msg string, fields ...field.Field) {
-	l.log(level, msg, fields...)
+	l.log(level, nil, msg, fields...)
 }
 
 func (l *MainLogger) Debug(msg string, fields ...field.Field) {
-	l.log(misc.LevelDebug, msg, fields...)
+	l.log(misc.LevelDebug, nil, msg, fields...)
 }
 
 func (l *MainLogger) Info(msg string, fields ...field.Field) {
-	l.log(misc.LevelInfo, msg, fields...)
+	l.log(misc.LevelInfo, nil, msg, fields...)
 }
 
 func (l *MainLogger) Warn(msg string, fields ...field.Field) {
-	l.log(misc.LevelWarn, msg, fields...)
+	l.log(misc.LevelWarn, nil, msg, fields...)
 }
 
 func (l *MainLogger) Error(msg string, fields ...field.Field) {
-	l.log(misc.LevelError, msg, fields...)
+	l.log(misc.LevelError, nil, msg, fields...)
 }
 
 func (l *MainLogger) Fatal(msg string, fields ...field.Field) {
-	l.log(misc.LevelFatal, msg, fields...)
+	l.log(misc.LevelFatal, nil, msg, fields...)
 }
 
 func (l *MainLogger) Panic(msg string, fields ...field.Field) {
-	l.log(misc.LevelPanic, msg, fields...)
+	l.log(misc.LevelPanic, nil, msg, fields...)
+}
+
+// Event emits the registered event with its level. Field with event ID is added to the record, required fields missing
+// both in fields and in the context of the logger are reported in additional error field.
+func (l *MainLogger) Event(evt Event, fields ...field.Field) {
+	l.log(evt.Level, &evt, evt.Message, fields...)
+}
+
+// WithContext returns a copy of the logger with fields stored in ctx. Trace-context of ctx is used as well and the
+// fields returned by Config.ContextExtractors are appended.
+func (l *MainLogger) WithContext(ctx context.Context) *MainLogger {
+	loggerFields, ctxFields := field.FieldsFromContext(l.ctx), field.FieldsFromContext(ctx)
+
+	existing := make(field.Fields, 0, len(loggerFields)+len(ctxFields))
+	existing = append(append(existing, loggerFields...), ctxFields...)
+
+	fields := make(field.Fields, 0, len(ctxFields))
+	fields = append(append(fields, ctxFields...), extractFields(l.cfg.ContextExtractors, ctx, existing)...)
+
+	loggerCtx := field.WithContextFields(l.ctx, fields...)
+	if tc, ok := tracecontext.FromContext(ctx); ok {
+		loggerCtx = tracecontext.WithContext(loggerCtx, tc)
+	}
+
+	return &MainLogger{
+		cfg:    l.cfg,
+		gw:     l.gw,
+		ctx:    loggerCtx,
+		masker: l.masker,
+		logger: l.logger,
+	}
 }
 
 func L(optionalCtx ...context.Context) *MainLogger {
@@ -89,13 +146,7 @@ func L(optionalCtx ...context.Context) *MainLogger {
 	_globalMu.RLock()
 
 	if len(optionalCtx) != 0 {
-		l = &MainLogger{
-			_globalL.cfg,
-			_globalL.gw,
-			field.WithContextFields(_globalL.ctx, field.FieldsFromContext(optionalCtx[0])...),
-			_globalL.masker,
-			_globalL.logger,
-		}
+		l = _globalL.WithContext(optionalCtx[0])
 	} else {
 		l = _globalL
 	}
@@ -121,12 +172,14 @@ func NewProduction(ctx context.Context, cfg Config, contacts ...contactpoints.Co
 	} else if tmpl, err = templates.FromPattern(cfg.Template.Pattern); err != nil || tmpl == nil {
 		return nil, err
 	}
-	gw = gateway.CreateGateway().WithTemplate(tmpl).WithContactPoints(true, contacts...)
+	gw = gateway.CreateGateway().WithTemplate(tmpl).WithContactPoints(true, contacts...).WithMasker(masker)
 
 	// TODO: (???)
 	cfg.Writer = misc.Coalesce[io.Writer](cfg.Writer, _defaultWriter)
 
-	fields := field.FieldsFromContext(ctx)
+	ctxFields := field.FieldsFromContext(ctx)
+	fields := make(field.Fields, 0, len(ctxFields))
+	fields = append(append(fields, ctxFields...), extractFields(cfg.ContextExtractors, ctx, ctxFields)...)
 	if cfg.Source != "" {
 		fields = fields.WithOptions(field.OptionSource(cfg.Source))
 	}
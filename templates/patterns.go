@@ -15,7 +15,11 @@ const (
 
 **Origin:**
 *{{ .LogSource }}*
-{{ if len .LogContextFields }}
+{{ if .TraceID }}
+**Trace:**
+*{{ .TraceID }}/{{ .SpanID }}*
+{{ end }}
+{{- if len .LogContextFields }}
 **Context Fields:**
 {{- end }}
 {{ range .LogContextFields }}
@@ -38,4 +42,6 @@ type Placeholder struct {
 	LogContextFields field.Fields
 	LogFields        []field.Field
 	LogMessage       string
+	TraceID          string
+	SpanID           string
 }
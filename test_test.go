@@ -1,7 +1,17 @@
 package mlogger
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Alp4ka/mlogger/field"
+	"github.com/Alp4ka/mlogger/jsonsecurity"
+	"github.com/Alp4ka/mlogger/misc"
+	"github.com/Alp4ka/mlogger/tracecontext"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -19,3 +29,286 @@ func TestLogger(t *testing.T) {
 	logger.Info("pisya-popa")
 	//assert.Equal(t, )
 }
+
+func TestLogger_ShouldNotEvaluateLazyField_WhenLevelIsFiltered(t *testing.T) {
+	calls := 0
+	logger, err := NewProduction(context.TODO(), Config{Level: misc.LevelError, Writer: io.Discard})
+	if err != nil {
+		panic(err)
+	}
+
+	logger.Info("filtered", field.Lazy("dump", func() any { calls++; return "expensive" }))
+	assert.Equal(t, 0, calls)
+
+	logger.Error("emitted", field.Lazy("dump", func() any { calls++; return "expensive" }))
+	assert.Equal(t, 1, calls)
+}
+
+type testContactPoint struct {
+	msgs chan string
+}
+
+func (cp *testContactPoint) Msg(_ context.Context, _ misc.Level, msg string) error {
+	cp.msgs <- msg
+	return nil
+}
+
+func TestLogger_ShouldApplyFieldVisibility(t *testing.T) {
+	cp := &testContactPoint{msgs: make(chan string, 1)}
+	buf := new(bytes.Buffer)
+	logger, err := NewProduction(context.TODO(), Config{Level: misc.LevelInfo, Writer: buf}, cp)
+	if err != nil {
+		panic(err)
+	}
+
+	logger.Info(
+		"visibility",
+		field.LocalOnly(field.String("local_only", "local_value")),
+		field.AlertOnly(field.String("alert_only", "alert_value")),
+		field.Sensitive(field.String("sensitive", "sensitive_value")),
+	)
+	alert := <-cp.msgs
+
+	assert.Contains(t, buf.String(), "local_value")
+	assert.NotContains(t, buf.String(), "alert_value")
+	assert.Contains(t, buf.String(), "sensitive_value")
+
+	assert.NotContains(t, alert, "local_value")
+	assert.Contains(t, alert, "alert_value")
+	assert.NotContains(t, alert, "sensitive_value")
+	assert.Contains(t, alert, field.RedactedValue)
+}
+
+// unregisterEvent removes the event from the catalog, so the tests can be run repeatedly.
+func unregisterEvent(id string) {
+	_eventsMu.Lock()
+	defer _eventsMu.Unlock()
+
+	delete(_events, id)
+}
+
+func TestLogger_ShouldEmitEvent(t *testing.T) {
+	evt := MustRegisterEvent(Event{
+		ID:       "test.login_failed",
+		Level:    misc.LevelWarn,
+		Message:  "login failed for {{ .user_id }}",
+		Required: []string{"user_id", "reason"},
+		Alert:    AlertPolicyNever,
+	})
+	t.Cleanup(func() { unregisterEvent(evt.ID) })
+
+	buf := new(bytes.Buffer)
+	logger, err := NewProduction(context.TODO(), Config{Level: misc.LevelInfo, Writer: buf})
+	if err != nil {
+		panic(err)
+	}
+
+	logger.Event(evt, field.String("user_id", "42"))
+
+	assert.Contains(t, buf.String(), `"msg":"login failed for 42"`)
+	assert.Contains(t, buf.String(), `"event_id":"test.login_failed"`)
+	assert.Contains(t, buf.String(), `"event_id_FAIL":"missing required fields: reason"`)
+	assert.Contains(t, Events(), evt)
+
+	_, err = RegisterEvent(Event{ID: "test.login_failed"})
+	assert.Error(t, err)
+}
+
+func TestLogger_ShouldEmitEvent_WithContextFields(t *testing.T) {
+	evt := MustRegisterEvent(Event{
+		ID:       "test.payment_failed",
+		Level:    misc.LevelWarn,
+		Message:  "payment failed for {{ .user_id }}",
+		Required: []string{"user_id", "reason"},
+		Alert:    AlertPolicyNever,
+	})
+	t.Cleanup(func() { unregisterEvent(evt.ID) })
+
+	tc, err := tracecontext.Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "")
+	if err != nil {
+		panic(err)
+	}
+
+	buf := new(bytes.Buffer)
+	ctx := field.WithContextFields(tracecontext.WithContext(context.TODO(), tc), field.String("user_id", "42"))
+	logger, err := NewProduction(ctx, Config{Level: misc.LevelInfo, Writer: buf})
+	if err != nil {
+		panic(err)
+	}
+
+	fields := make([]field.Field, 1, 8)
+	fields[0] = field.String("reason", "declined")
+	logger.Event(evt, fields...)
+
+	assert.Contains(t, buf.String(), `"msg":"payment failed for 42"`)
+	assert.NotContains(t, buf.String(), `"event_id_FAIL"`)
+	assert.Equal(t, field.String("reason", "declined"), fields[0])
+	assert.Equal(t, field.Field{}, fields[:2][1])
+}
+
+func TestLogger_ShouldAddTraceContext(t *testing.T) {
+	tc, err := tracecontext.Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "")
+	if err != nil {
+		panic(err)
+	}
+
+	cp := &testContactPoint{msgs: make(chan string, 1)}
+	buf := new(bytes.Buffer)
+	logger, err := NewProduction(tracecontext.WithContext(context.TODO(), tc), Config{Writer: buf}, cp)
+	if err != nil {
+		panic(err)
+	}
+
+	logger.Info("traced")
+	alert := <-cp.msgs
+
+	assert.Contains(t, buf.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"`)
+	assert.Contains(t, alert, "4bf92f3577b34da6a3ce929d0e0e4736/00f067aa0ba902b7")
+}
+
+func TestLogger_ShouldApplyContextExtractors(t *testing.T) {
+	type requestIDKey struct{}
+
+	buf := new(bytes.Buffer)
+	logger, err := NewProduction(
+		field.WithContextFields(context.TODO(), field.String("tenant_id", "logger")),
+		Config{
+			Writer: buf,
+			ContextExtractors: []ContextExtractor{
+				func(ctx context.Context) []field.Field {
+					requestID, ok := ctx.Value(requestIDKey{}).(string)
+					if !ok {
+						return nil
+					}
+					return []field.Field{
+						field.String("request_id", requestID),
+						field.String("tenant_id", "extractor"),
+					}
+				},
+				func(ctx context.Context) []field.Field {
+					if ctx.Value(requestIDKey{}) == nil {
+						return nil
+					}
+					return []field.Field{field.String("request_id", "second")}
+				},
+			},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.WithValue(context.TODO(), requestIDKey{}, "req-1")
+	logger.WithContext(ctx).Info("extracted")
+
+	assert.Contains(t, buf.String(), `"tenant_id":"logger","request_id":"req-1","caller"`)
+}
+
+func TestNewProduction_ShouldApplyContextExtractors(t *testing.T) {
+	type requestIDKey struct{}
+
+	buf := new(bytes.Buffer)
+	logger, err := NewProduction(
+		context.WithValue(context.TODO(), requestIDKey{}, "req-1"),
+		Config{
+			Writer: buf,
+			ContextExtractors: []ContextExtractor{
+				func(ctx context.Context) []field.Field {
+					return []field.Field{field.String("request_id", ctx.Value(requestIDKey{}).(string))}
+				},
+			},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	logger.Info("extracted")
+
+	assert.Contains(t, buf.String(), `"request_id":"req-1"`)
+}
+
+func TestLogger_ShouldRedactMessageAndStringFields(t *testing.T) {
+	cp := &testContactPoint{msgs: make(chan string, 1)}
+	buf := new(bytes.Buffer)
+	logger, err := NewProduction(context.TODO(), Config{
+		Writer: buf,
+		JSONSecurity: jsonsecurity.Config{
+			Redaction: jsonsecurity.RedactionConfig{Enabled: true},
+		},
+	}, cp)
+	if err != nil {
+		panic(err)
+	}
+
+	logger.Info(
+		"login failed for john.doe@example.com",
+		field.String("card", "paid with 4111111111111111"),
+		field.Error(fmt.Errorf("user john.doe@example.com not found")),
+	)
+	alert := <-cp.msgs
+
+	assert.NotContains(t, buf.String(), "john.doe@example.com")
+	assert.NotContains(t, buf.String(), "4111111111111111")
+	assert.Contains(t, buf.String(), `"msg":"login failed for j*******@example.com"`)
+	assert.Contains(t, buf.String(), `"card":"paid with 411111******1111"`)
+	assert.NotContains(t, alert, "john.doe@example.com")
+	assert.NotContains(t, alert, "4111111111111111")
+}
+
+func TestLogger_ShouldMaskContextFieldsOfAlert(t *testing.T) {
+	cp := &testContactPoint{msgs: make(chan string, 1)}
+	logger, err := NewProduction(context.TODO(), Config{
+		Level:  misc.LevelInfo,
+		Writer: io.Discard,
+		JSONSecurity: jsonsecurity.Config{
+			Triggers: map[string]jsonsecurity.TriggerOpts{
+				"password": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelPassword},
+			},
+		},
+	}, cp)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := field.WithContextFields(context.TODO(), field.String("password", "qwerty123"))
+	logger.WithContext(ctx).Info("context")
+	alert := <-cp.msgs
+
+	assert.NotContains(t, alert, "qwerty123")
+	assert.Contains(t, alert, "*********")
+}
+
+func TestLogger_ShouldDecryptLine_WhenTokensAreEscaped(t *testing.T) {
+	keys := map[string][]byte{"support": []byte("0123456789abcdef")}
+
+	buf := new(bytes.Buffer)
+	logger, err := NewProduction(context.TODO(), Config{
+		Writer: buf,
+		JSONSecurity: jsonsecurity.Config{
+			MaxDepth: 5,
+			Triggers: map[string]jsonsecurity.TriggerOpts{
+				"password": {ShouldAppear: true, MaskMethod: jsonsecurity.MaskerLabelEncrypt},
+			},
+			Encrypt: jsonsecurity.EncryptConfig{KeyID: "support", Key: keys["support"]},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	body := `{"login":"john","password":"hu\"nter<22","attempts":3}`
+	logger.Info("login", field.String("password", "qwerty"), field.JSONEscapeSecure("body", []byte(body)))
+
+	line := strings.TrimSpace(buf.String())
+	assert.NotContains(t, line, "qwerty")
+	assert.NotContains(t, line, "nter")
+
+	restored, err := jsonsecurity.DecryptLine(line, keys)
+	assert.NoError(t, err)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(restored), &record))
+	assert.Equal(t, "qwerty", record["password"])
+	assert.JSONEq(t, body, record["body"].(string))
+}
@@ -0,0 +1,124 @@
+package tracecontext
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxStateMembers is a maximum number of list-members in tracestate.
+const maxStateMembers = 32
+
+// Member is a single key-value pair of tracestate.
+type Member struct {
+	Key   string
+	Value string
+}
+
+// State stores tracestate list-members in their order.
+type State []Member
+
+// String returns the value of tracestate header.
+func (s State) String() string {
+	parts := make([]string, 0, len(s))
+	for _, m := range s {
+		parts = append(parts, m.Key+"="+m.Value)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// Get returns the value of the member by key.
+func (s State) Get(key string) (string, bool) {
+	for _, m := range s {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// With returns State with the member moved (or added) to the beginning as the specification requires on update.
+func (s State) With(key string, value string) (State, error) {
+	if !isValidStateKey(key) || !isValidStateValue(value) {
+		return nil, fmt.Errorf("invalid tracestate member: %s=%s", key, value)
+	}
+
+	ret := make(State, 0, len(s)+1)
+	ret = append(ret, Member{Key: key, Value: value})
+	for _, m := range s {
+		if m.Key != key && len(ret) < maxStateMembers {
+			ret = append(ret, m)
+		}
+	}
+
+	return ret, nil
+}
+
+// ParseState parses tracestate header value. Empty list-members are skipped.
+func ParseState(tracestate string) (State, error) {
+	state := make(State, 0)
+	if strings.TrimSpace(tracestate) == "" {
+		return state, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(tracestate, ",") {
+		part = strings.Trim(part, " \t")
+		if part == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || !isValidStateKey(key) || !isValidStateValue(value) {
+			return nil, fmt.Errorf("invalid tracestate member: %s", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicated tracestate key: %s", key)
+		}
+		seen[key] = true
+
+		state = append(state, Member{Key: key, Value: value})
+	}
+
+	if len(state) > maxStateMembers {
+		return nil, fmt.Errorf("too many tracestate members: %d", len(state))
+	}
+
+	return state, nil
+}
+
+// isValidStateKey validates simple-key or multi-tenant-key (tenant@system).
+func isValidStateKey(key string) bool {
+	if key == "" || len(key) > 256 {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '_' || c == '-' || c == '*' || c == '/' || c == '@':
+		default:
+			return false
+		}
+	}
+
+	return strings.Count(key, "@") <= 1
+}
+
+// isValidStateValue validates value consisting of printable ascii characters except ',' and '='.
+func isValidStateValue(value string) bool {
+	if value == "" || len(value) > 256 || value[len(value)-1] == ' ' {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < 0x20 || c > 0x7e || c == ',' || c == '=' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,191 @@
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+
+	// FlagSampled is the only flag defined by W3C trace-context.
+	FlagSampled byte = 0x01
+
+	version         = "00"
+	invalidVersion  = "ff"
+	traceparentSize = 55
+)
+
+type contextKey struct{}
+
+// ContextKeyTraceContext is a key TraceContext is stored in context with. It's used the same way as
+// field.ContextKeyLogFields is.
+var ContextKeyTraceContext = contextKey{}
+
+// TraceID identifies the whole trace.
+type TraceID [16]byte
+
+// String returns lowercase hex representation of TraceID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether TraceID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID identifies a single span (parent-id in terms of W3C).
+type SpanID [8]byte
+
+// String returns lowercase hex representation of SpanID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether SpanID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// TraceContext stores values of W3C traceparent and tracestate headers.
+type TraceContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	State   State
+}
+
+// IsValid reports whether both TraceID and SpanID are valid.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID.IsValid() && tc.SpanID.IsValid()
+}
+
+// IsSampled reports whether the sampled flag is set.
+func (tc TraceContext) IsSampled() bool {
+	return tc.Flags&FlagSampled != 0
+}
+
+// Traceparent returns the value of traceparent header.
+//
+// Example: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func (tc TraceContext) Traceparent() string {
+	return fmt.Sprintf("%s-%s-%s-%02x", version, tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// Child returns TraceContext of the same trace with new random SpanID.
+func (tc TraceContext) Child() (TraceContext, error) {
+	if _, err := rand.Read(tc.SpanID[:]); err != nil {
+		return TraceContext{}, err
+	}
+
+	return tc, nil
+}
+
+// New returns sampled TraceContext with random TraceID and SpanID.
+func New() (TraceContext, error) {
+	tc := TraceContext{Flags: FlagSampled}
+	if _, err := rand.Read(tc.TraceID[:]); err != nil {
+		return TraceContext{}, err
+	}
+
+	return tc.Child()
+}
+
+// Parse parses traceparent and tracestate header values. Malformed tracestate is ignored as the specification
+// requires.
+func Parse(traceparent string, tracestate string) (TraceContext, error) {
+	var tc TraceContext
+
+	traceparent = strings.TrimSpace(traceparent)
+	if len(traceparent) < traceparentSize {
+		return TraceContext{}, fmt.Errorf("invalid traceparent length: %d", len(traceparent))
+	}
+
+	ver := traceparent[0:2]
+	if !isLowerHex(ver) || ver == invalidVersion {
+		return TraceContext{}, fmt.Errorf("invalid traceparent version: %s", ver)
+	}
+	// Future versions may append fields, but the version 00 must have exact length.
+	if (ver == version && len(traceparent) != traceparentSize) ||
+		(len(traceparent) > traceparentSize && traceparent[traceparentSize] != '-') {
+		return TraceContext{}, fmt.Errorf("invalid traceparent length: %d", len(traceparent))
+	}
+
+	if traceparent[2] != '-' || traceparent[35] != '-' || traceparent[52] != '-' {
+		return TraceContext{}, fmt.Errorf("invalid traceparent delimiters")
+	}
+
+	if err := decodeHex(tc.TraceID[:], traceparent[3:35]); err != nil || !tc.TraceID.IsValid() {
+		return TraceContext{}, fmt.Errorf("invalid trace-id: %s", traceparent[3:35])
+	}
+	if err := decodeHex(tc.SpanID[:], traceparent[36:52]); err != nil || !tc.SpanID.IsValid() {
+		return TraceContext{}, fmt.Errorf("invalid parent-id: %s", traceparent[36:52])
+	}
+
+	flags := make([]byte, 1)
+	if err := decodeHex(flags, traceparent[53:55]); err != nil {
+		return TraceContext{}, fmt.Errorf("invalid trace-flags: %s", traceparent[53:55])
+	}
+	tc.Flags = flags[0]
+
+	if state, err := ParseState(tracestate); err == nil {
+		tc.State = state
+	}
+
+	return tc, nil
+}
+
+// FromHeader parses trace-context from http headers.
+func FromHeader(header http.Header) (TraceContext, error) {
+	return Parse(header.Get(HeaderTraceparent), strings.Join(header.Values(HeaderTracestate), ","))
+}
+
+// Inject sets traceparent and tracestate http headers.
+func Inject(header http.Header, tc TraceContext) {
+	header.Set(HeaderTraceparent, tc.Traceparent())
+	if len(tc.State) != 0 {
+		header.Set(HeaderTracestate, tc.State.String())
+	} else {
+		header.Del(HeaderTracestate)
+	}
+}
+
+// WithContext stores TraceContext in context.
+func WithContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, ContextKeyTraceContext, tc)
+}
+
+// FromContext extracts TraceContext from context. If ctx is nil, use context.Background()
+func FromContext(ctx context.Context) (TraceContext, bool) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tc, ok := ctx.Value(ContextKeyTraceContext).(TraceContext)
+	return tc, ok && tc.IsValid()
+}
+
+func decodeHex(dst []byte, src string) error {
+	if !isLowerHex(src) {
+		return fmt.Errorf("not a lowercase hex: %s", src)
+	}
+
+	_, err := hex.Decode(dst, []byte(src))
+	return err
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9') && !(s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
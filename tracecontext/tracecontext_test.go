@@ -0,0 +1,73 @@
+package tracecontext
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestParse_ShouldParseValidTraceparent(t *testing.T) {
+	tc, err := Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "congo=t61rcWkgMzE, rojo=00f067aa0ba902b7")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", tc.SpanID.String())
+	assert.True(t, tc.IsSampled())
+	assert.Equal(t, "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7", tc.State.String())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", tc.Traceparent())
+}
+
+func TestParse_ShouldReturnError_WhenTraceparentIsInvalid(t *testing.T) {
+	for _, traceparent := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+	} {
+		_, err := Parse(traceparent, "")
+		assert.Error(t, err, traceparent)
+	}
+}
+
+func TestParse_ShouldAcceptFutureVersion(t *testing.T) {
+	tc, err := Parse("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future", "")
+
+	assert.NoError(t, err)
+	assert.False(t, tc.IsSampled())
+}
+
+func TestParse_ShouldIgnoreInvalidTracestate(t *testing.T) {
+	tc, err := Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "Invalid Key=1")
+
+	assert.NoError(t, err)
+	assert.Empty(t, tc.State)
+}
+
+func TestInject_ShouldRoundTrip(t *testing.T) {
+	tc, err := New()
+	assert.NoError(t, err)
+	tc.State, err = tc.State.With("vendor", "value")
+	assert.NoError(t, err)
+
+	header := http.Header{}
+	Inject(header, tc)
+	parsed, err := FromHeader(header)
+
+	assert.NoError(t, err)
+	assert.Equal(t, tc, parsed)
+}
+
+func TestFromContext_ShouldReturnFalse_WhenNotStored(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	tc, _ := New()
+	stored, ok := FromContext(WithContext(context.Background(), tc))
+	assert.True(t, ok)
+	assert.Equal(t, tc, stored)
+}